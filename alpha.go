@@ -0,0 +1,58 @@
+package concaveman
+
+import (
+	"math"
+
+	"github.com/wsw0108/concaveman-go/delaunay"
+)
+
+// AlphaShape computes the alpha shape of the points, the union of the Delaunay
+// triangles whose circumcircle has a radius of at most alpha, and returns its
+// connected parts as polygons with holes. Unlike the concavity of Concaveman,
+// alpha is an absolute length: the larger it is, the closer the shape gets to
+// the convex hull. Rings wind like the hulls of Concaveman, with holes the
+// other way, and may touch themselves at a vertex where two triangles of the
+// shape meet only there. It returns nil for degenerate input.
+func AlphaShape(points []Point, alpha float64) []Polygon {
+	if _, err := validate(points); err != nil {
+		return nil
+	}
+	t := delaunay.New(points)
+	in := make([]bool, t.Len())
+	for i := range in {
+		in[i] = t.Circumradius(i) <= alpha
+	}
+
+	labels, count := components(t, in)
+	if count == 0 {
+		return nil
+	}
+	loops, loopLabels := boundaryLoops(t, labels)
+
+	// the outer ring of a part is its largest loop, the others are holes
+	polygons := make([]Polygon, count)
+	areas := make([]float64, count)
+	for k, loop := range loops {
+		c := loopLabels[k]
+		ring := make([]Point, 0, len(loop)+1)
+		for _, i := range loop {
+			ring = append(ring, points[i])
+		}
+		ring = append(ring, ring[0])
+		if area := math.Abs(RingArea(ring)); area > areas[c] {
+			areas[c] = area
+			polygons[c] = append(Polygon{ring}, polygons[c]...)
+		} else {
+			polygons[c] = append(polygons[c], ring)
+		}
+	}
+
+	for _, poly := range polygons {
+		for i, ring := range poly {
+			if (RingArea(ring) < 0) != (i == 0) {
+				reverse(ring)
+			}
+		}
+	}
+	return polygons
+}
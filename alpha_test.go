@@ -0,0 +1,65 @@
+package concaveman_test
+
+import (
+	"math"
+	"testing"
+
+	"github.com/wsw0108/concaveman-go"
+)
+
+func TestAlphaShape(t *testing.T) {
+	points := lakeGrid()
+
+	// the grid cells make it through, the lake does not
+	shape := concaveman.AlphaShape(points, 1)
+	if len(shape) != 1 || len(shape[0]) != 2 {
+		t.Fatalf("TestAlphaShape: got %d polygons", len(shape))
+	}
+	outer, hole := shape[0][0], shape[0][1]
+	if area := concaveman.RingArea(outer); math.Abs(area) != 400 {
+		t.Errorf("TestAlphaShape: outer area = %v, want 400", area)
+	}
+	// the lake corners are cut off by cell halves
+	if area := concaveman.RingArea(hole); math.Abs(area) != 98 {
+		t.Errorf("TestAlphaShape: hole area = %v, want 98", area)
+	}
+	hull := concaveman.Concaveman(points)
+	if (concaveman.RingArea(outer) < 0) != (concaveman.RingArea(hull) < 0) {
+		t.Error("TestAlphaShape: outer ring winds unlike Concaveman")
+	}
+	if (concaveman.RingArea(outer) < 0) == (concaveman.RingArea(hole) < 0) {
+		t.Error("TestAlphaShape: hole winds like the outer ring")
+	}
+
+	// large enough to span the lake
+	shape = concaveman.AlphaShape(points, 100)
+	if len(shape) != 1 || len(shape[0]) != 1 {
+		t.Errorf("TestAlphaShape: expected a single ring, got %v", shape)
+	}
+
+	if shape := concaveman.AlphaShape(points, 0.5); shape != nil {
+		t.Errorf("TestAlphaShape: expected no triangles, got %v", shape)
+	}
+}
+
+func TestAlphaShapeParts(t *testing.T) {
+	var points []concaveman.Point
+	for _, p := range lakeGrid() {
+		points = append(points, p, concaveman.Point{p[0] + 100, p[1]})
+	}
+	shape := concaveman.AlphaShape(points, 1)
+	if len(shape) != 2 {
+		t.Fatalf("TestAlphaShapeParts: got %d polygons, want 2", len(shape))
+	}
+	for _, poly := range shape {
+		if len(poly) != 2 || math.Abs(concaveman.RingArea(poly[0])) != 400 {
+			t.Errorf("TestAlphaShapeParts: got %v", poly)
+		}
+	}
+
+	for _, points := range [][]concaveman.Point{nil, {{0, 0}, {1, 1}, {2, 2}}, {{0, 0}, {math.NaN(), 1}, {1, 0}}} {
+		if shape := concaveman.AlphaShape(points, 1); shape != nil {
+			t.Errorf("TestAlphaShapeParts: AlphaShape(%v) = %v", points, shape)
+		}
+	}
+}
@@ -0,0 +1,220 @@
+package main
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/wsw0108/concaveman-go"
+	"github.com/wsw0108/concaveman-go/geojson"
+	"github.com/wsw0108/concaveman-go/wkb"
+	"github.com/wsw0108/concaveman-go/wkt"
+)
+
+type inputFormat int
+
+const (
+	formatAuto inputFormat = iota
+	formatJSON
+	formatCSV
+	formatGeoJSON
+	formatWKT
+	formatWKB
+	formatWKBHex
+)
+
+func parseFormat(s string) (inputFormat, bool) {
+	switch strings.ToLower(s) {
+	case "auto":
+		return formatAuto, true
+	case "json":
+		return formatJSON, true
+	case "csv":
+		return formatCSV, true
+	case "geojson":
+		return formatGeoJSON, true
+	case "wkt":
+		return formatWKT, true
+	case "wkb":
+		return formatWKB, true
+	case "wkb-hex":
+		return formatWKBHex, true
+	}
+	return 0, false
+}
+
+// parseError tells malformed input apart from I/O errors
+type parseError struct {
+	name string
+	err  error
+}
+
+func (e *parseError) Error() string {
+	return fmt.Sprintf("%s: %v", e.name, e.err)
+}
+
+func (e *parseError) Unwrap() error {
+	return e.err
+}
+
+func readInputs(names []string, format inputFormat, stdin io.Reader) ([]concaveman.Point, error) {
+	if len(names) == 0 {
+		names = []string{"-"}
+	}
+	var points []concaveman.Point
+	for _, name := range names {
+		var data []byte
+		var err error
+		if name == "-" {
+			data, err = io.ReadAll(stdin)
+		} else {
+			data, err = os.ReadFile(name)
+		}
+		if err != nil {
+			return nil, err
+		}
+		f := format
+		if f == formatAuto {
+			f = detectFormat(name, data)
+		}
+		ps, err := parse(data, f)
+		if err != nil {
+			if name == "-" {
+				name = "<stdin>"
+			}
+			return nil, &parseError{name, err}
+		}
+		points = append(points, ps...)
+	}
+	return points, nil
+}
+
+// detectFormat guesses the format from the file extension, or else from the
+// first meaningful byte of the content
+func detectFormat(name string, data []byte) inputFormat {
+	switch strings.ToLower(filepath.Ext(name)) {
+	case ".geojson":
+		return formatGeoJSON
+	case ".csv":
+		return formatCSV
+	case ".wkt":
+		return formatWKT
+	case ".wkb":
+		return formatWKB
+	}
+	if len(data) > 0 && data[0] <= 1 {
+		return formatWKB
+	}
+	trimmed := bytes.TrimSpace(data)
+	if len(trimmed) == 0 {
+		return formatJSON
+	}
+	if isHexWKB(trimmed) {
+		return formatWKBHex
+	}
+	switch c := trimmed[0]; {
+	case c == '[':
+		return formatJSON
+	case c == '{':
+		return formatGeoJSON
+	case c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z':
+		// a letter starts WKT, or a CSV header
+		upper := strings.ToUpper(string(trimmed[:min(len(trimmed), 32)]))
+		for _, prefix := range []string{"SRID=", "POINT", "MULTI", "LINESTRING", "POLYGON", "GEOMETRYCOLLECTION"} {
+			if strings.HasPrefix(upper, prefix) {
+				return formatWKT
+			}
+		}
+		return formatCSV
+	default:
+		return formatCSV
+	}
+}
+
+// isHexWKB reports whether data is hex starting with a WKB byte order byte
+func isHexWKB(data []byte) bool {
+	if len(data)%2 != 0 || !bytes.HasPrefix(data, []byte("00")) && !bytes.HasPrefix(data, []byte("01")) {
+		return false
+	}
+	for _, c := range data {
+		if !(c >= '0' && c <= '9' || c >= 'a' && c <= 'f' || c >= 'A' && c <= 'F') {
+			return false
+		}
+	}
+	return true
+}
+
+func min(a, b int) int {
+	if a < b {
+		return a
+	}
+	return b
+}
+
+func parse(data []byte, format inputFormat) ([]concaveman.Point, error) {
+	if len(bytes.TrimSpace(data)) == 0 {
+		// no points, as when a pipe stays empty
+		return nil, nil
+	}
+	switch format {
+	case formatJSON:
+		var points []concaveman.Point
+		if err := json.Unmarshal(data, &points); err != nil {
+			return nil, err
+		}
+		return points, nil
+	case formatCSV:
+		return parseCSV(data)
+	case formatGeoJSON:
+		return geojson.DecodePoints(data)
+	case formatWKT:
+		return wkt.Decode(string(data))
+	case formatWKB:
+		points, _, err := wkb.Decode(data)
+		return points, err
+	case formatWKBHex:
+		b, err := hex.DecodeString(string(bytes.TrimSpace(data)))
+		if err != nil {
+			return nil, err
+		}
+		points, _, err := wkb.Decode(b)
+		return points, err
+	}
+	panic("unreachable")
+}
+
+// parseCSV reads x and y from the first two columns, skipping a header row
+func parseCSV(data []byte) ([]concaveman.Point, error) {
+	r := csv.NewReader(bytes.NewReader(data))
+	r.FieldsPerRecord = -1
+	r.TrimLeadingSpace = true
+	var points []concaveman.Point
+	for row := 1; ; row++ {
+		record, err := r.Read()
+		if err == io.EOF {
+			return points, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		if len(record) < 2 {
+			return nil, fmt.Errorf("row %d: expected at least 2 columns", row)
+		}
+		x, errX := strconv.ParseFloat(record[0], 64)
+		y, errY := strconv.ParseFloat(record[1], 64)
+		if errX != nil || errY != nil {
+			if row == 1 {
+				continue
+			}
+			return nil, fmt.Errorf("row %d: invalid coordinates %q, %q", row, record[0], record[1])
+		}
+		points = append(points, concaveman.Point{x, y})
+	}
+}
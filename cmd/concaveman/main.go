@@ -0,0 +1,138 @@
+// Command concaveman computes the concave hull of points read from files or
+// standard input.
+//
+// Usage:
+//
+//	concaveman [flags] [file ...]
+//	concaveman render [flags] [file ...]
+//
+// Points are read from the given files, or from standard input when there
+// are none or a file is named "-". Supported input formats are JSON arrays of
+// [x, y] pairs, CSV with x and y in the first two columns, GeoJSON, WKT, and
+// WKB in binary or hex; the format is guessed from the file extension or the
+// content unless set with -in. The hull is written to standard output in the
+// format chosen with -out.
+//
+// The render subcommand draws the input points, the starting convex hull,
+// the quadrilateral used to cull points for it and the concave hull, as SVG
+// or PNG depending on -format or the extension of the -o file.
+//
+// Exit codes are 0 on success, 1 on I/O errors, 2 on bad usage, 3 when the
+// input cannot be parsed and 4 when it is too degenerate to compute a hull.
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/wsw0108/concaveman-go"
+)
+
+const (
+	exitOK = iota
+	exitError
+	exitUsage
+	exitParse
+	exitDegenerate
+)
+
+func main() {
+	os.Exit(run(os.Args[1:], os.Stdin, os.Stdout, os.Stderr))
+}
+
+// hullFlags are the flags shared by all subcommands
+type hullFlags struct {
+	concavity       *float64
+	lengthThreshold *float64
+	minHoleArea     *float64
+	geodesic        *bool
+	staticIndex     *bool
+	in              *string
+}
+
+func newHullFlags(flags *flag.FlagSet) hullFlags {
+	return hullFlags{
+		concavity:       flags.Float64("concavity", 2, "relative measure of concavity; higher value means simpler hull"),
+		lengthThreshold: flags.Float64("length-threshold", 0, "edges shorter than this are not drilled down further"),
+		minHoleArea:     flags.Float64("min-hole-area", 0, "emit empty regions that can hold a circle of this area as holes"),
+		geodesic:        flags.Bool("geodesic", false, "treat points as lon/lat degrees and lengths as meters"),
+		staticIndex:     flags.Bool("static-index", false, "index the points with a packed Hilbert R-tree"),
+		in:              flags.String("in", "auto", "input format: auto, json, csv, geojson, wkt, wkb or wkb-hex"),
+	}
+}
+
+func (f hullFlags) options() concaveman.Options {
+	return concaveman.Options{
+		Concavity:       *f.concavity,
+		LengthThreshold: *f.lengthThreshold,
+		MinHoleArea:     *f.minHoleArea,
+		Geodesic:        *f.geodesic,
+		StaticIndex:     *f.staticIndex,
+	}
+}
+
+// load reads the input points, returning a non-zero exit code if it fails
+func (f hullFlags) load(names []string, stdin io.Reader, stderr io.Writer) ([]concaveman.Point, int) {
+	format, ok := parseFormat(*f.in)
+	if !ok {
+		fmt.Fprintf(stderr, "concaveman: unknown input format %q\n", *f.in)
+		return nil, exitUsage
+	}
+
+	points, err := readInputs(names, format, stdin)
+	if err != nil {
+		fmt.Fprintf(stderr, "concaveman: %v\n", err)
+		var perr *parseError
+		if errors.As(err, &perr) {
+			return nil, exitParse
+		}
+		return nil, exitError
+	}
+	return points, exitOK
+}
+
+func run(args []string, stdin io.Reader, stdout, stderr io.Writer) int {
+	if len(args) > 0 && args[0] == "render" {
+		return runRender(args[1:], stdin, stdout, stderr)
+	}
+
+	flags := flag.NewFlagSet("concaveman", flag.ContinueOnError)
+	flags.SetOutput(stderr)
+	hf := newHullFlags(flags)
+	out := flags.String("out", "json", "output format: json, geojson, wkt, wkb or wkb-hex")
+	srid := flags.Int("srid", 0, "SRID to embed in wkb output")
+	flags.Usage = func() {
+		fmt.Fprintln(stderr, "usage: concaveman [flags] [file ...]")
+		fmt.Fprintln(stderr, "       concaveman render [flags] [file ...]")
+		flags.PrintDefaults()
+	}
+	if err := flags.Parse(args); err != nil {
+		return exitUsage
+	}
+
+	write, ok := writers[*out]
+	if !ok {
+		fmt.Fprintf(stderr, "concaveman: unknown output format %q\n", *out)
+		return exitUsage
+	}
+
+	points, code := hf.load(flags.Args(), stdin, stderr)
+	if code != exitOK {
+		return code
+	}
+	opt := hf.options()
+	poly, err := concaveman.ConcavemanPolygonE(points, opt)
+	if err != nil {
+		fmt.Fprintf(stderr, "concaveman: %v\n", err)
+		return exitDegenerate
+	}
+
+	if err := write(stdout, poly, len(points), opt, *srid); err != nil {
+		fmt.Fprintf(stderr, "concaveman: %v\n", err)
+		return exitError
+	}
+	return exitOK
+}
@@ -0,0 +1,124 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestRun(t *testing.T) {
+	var stdout, stderr bytes.Buffer
+	code := run([]string{"../../testdata/points-1k.json"}, nil, &stdout, &stderr)
+	if code != exitOK {
+		t.Fatalf("run() = %d: %s", code, stderr.String())
+	}
+	var got, want [][2]float64
+	if err := json.Unmarshal(stdout.Bytes(), &got); err != nil {
+		t.Fatal(err)
+	}
+	data, _ := os.ReadFile("../../testdata/points-1k-hull.json")
+	json.Unmarshal(data, &want)
+	if len(want) == 0 || !reflect.DeepEqual(got, want) {
+		t.Error("run() with default options")
+	}
+
+	want = nil
+	data, _ = os.ReadFile("../../testdata/points-1k-hull2.json")
+	json.Unmarshal(data, &want)
+	stdout.Reset()
+	code = run([]string{"-concavity", "3", "-length-threshold", "0.01", "../../testdata/points-1k.json"}, nil, &stdout, &stderr)
+	if code != exitOK {
+		t.Fatalf("run() = %d: %s", code, stderr.String())
+	}
+	got = nil
+	json.Unmarshal(stdout.Bytes(), &got)
+	if !reflect.DeepEqual(got, want) {
+		t.Error("run() with tuned options")
+	}
+}
+
+func TestRunFormats(t *testing.T) {
+	inputs := map[string]string{
+		"json":    "[[0,0],[2,0],[1,2],[1,1]]",
+		"csv":     "x,y\n0,0\n2,0\n1,2\n1,1\n",
+		"geojson": `{"type":"MultiPoint","coordinates":[[0,0],[2,0],[1,2],[1,1]]}`,
+		"wkt":     "MULTIPOINT (0 0, 2 0, 1 2, 1 1)",
+	}
+	for name, input := range inputs {
+		var stdout, stderr bytes.Buffer
+		code := run([]string{"-out", "wkt"}, strings.NewReader(input), &stdout, &stderr)
+		if code != exitOK {
+			t.Errorf("%s: run() = %d: %s", name, code, stderr.String())
+			continue
+		}
+		want := "POLYGON((2 0,0 0,1 2,1 1,2 0))\n"
+		if stdout.String() != want {
+			t.Errorf("%s: run() wrote %q, want %q", name, stdout.String(), want)
+		}
+	}
+
+	// wkb round trips through the binary and hex outputs
+	for _, out := range []string{"wkb", "wkb-hex"} {
+		var wkbOut, stdout, stderr bytes.Buffer
+		run([]string{"-out", out}, strings.NewReader(inputs["json"]), &wkbOut, &stderr)
+		code := run([]string{"-out", "wkt"}, &wkbOut, &stdout, &stderr)
+		if code != exitOK || stdout.String() != "POLYGON((2 0,0 0,1 2,1 1,2 0))\n" {
+			t.Errorf("%s: run() = %d, wrote %q", out, code, stdout.String())
+		}
+	}
+}
+
+func TestRunExitCodes(t *testing.T) {
+	tests := []struct {
+		name  string
+		args  []string
+		input string
+		code  int
+	}{
+		{"usage", []string{"-bogus"}, "", exitUsage},
+		{"format", []string{"-out", "svg"}, "", exitUsage},
+		{"parse", nil, "[[0,0],[1,", exitParse},
+		{"csv", []string{"-in", "csv"}, "0,0\n1,x\n", exitParse},
+		{"degenerate", nil, "[[0,0],[1,1],[2,2]]", exitDegenerate},
+		{"empty", nil, "", exitDegenerate},
+		{"missing", []string{"does-not-exist.json"}, "", exitError},
+	}
+	for _, tt := range tests {
+		var stdout, stderr bytes.Buffer
+		code := run(tt.args, strings.NewReader(tt.input), &stdout, &stderr)
+		if code != tt.code {
+			t.Errorf("%s: run() = %d, want %d (%s)", tt.name, code, tt.code, stderr.String())
+		}
+	}
+}
+
+func TestRunRender(t *testing.T) {
+	var stdout, stderr bytes.Buffer
+	code := run([]string{"render", "-width", "300", "-height", "200"}, strings.NewReader("[[0,0],[2,0],[1,2],[1,1]]"), &stdout, &stderr)
+	if code != exitOK {
+		t.Fatalf("run() = %d: %s", code, stderr.String())
+	}
+	if !strings.HasPrefix(stdout.String(), "<svg") {
+		t.Errorf("run() wrote %q", stdout.String())
+	}
+
+	name := t.TempDir() + "/hull.png"
+	code = run([]string{"render", "-o", name, "../../testdata/points-1k.json"}, nil, &stdout, &stderr)
+	if code != exitOK {
+		t.Fatalf("run() = %d: %s", code, stderr.String())
+	}
+	data, _ := os.ReadFile(name)
+	if !bytes.HasPrefix(data, []byte("\x89PNG")) {
+		t.Error("run() did not write a PNG")
+	}
+
+	for _, args := range [][]string{{"-format", "gif"}, {"-width", "19"}, {"-height", "100000"}} {
+		code = run(append([]string{"render"}, args...), strings.NewReader(""), &stdout, &stderr)
+		if code != exitUsage {
+			t.Errorf("run(%v) = %d, want %d", args, code, exitUsage)
+		}
+	}
+}
@@ -0,0 +1,60 @@
+package main
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+
+	"github.com/wsw0108/concaveman-go"
+	"github.com/wsw0108/concaveman-go/geojson"
+	"github.com/wsw0108/concaveman-go/wkb"
+	"github.com/wsw0108/concaveman-go/wkt"
+)
+
+type writer func(w io.Writer, poly concaveman.Polygon, pointCount int, opt concaveman.Options, srid int) error
+
+var writers = map[string]writer{
+	"json":    writeJSON,
+	"geojson": writeGeoJSON,
+	"wkt":     writeWKT,
+	"wkb":     writeWKB,
+	"wkb-hex": writeWKBHex,
+}
+
+// writeJSON writes the rings as arrays of [x, y] pairs; a polygon without
+// holes is written as a single ring, like the testdata hulls
+func writeJSON(w io.Writer, poly concaveman.Polygon, _ int, _ concaveman.Options, _ int) error {
+	enc := json.NewEncoder(w)
+	if len(poly) == 1 {
+		return enc.Encode(poly[0])
+	}
+	return enc.Encode(poly)
+}
+
+func writeGeoJSON(w io.Writer, poly concaveman.Polygon, pointCount int, opt concaveman.Options, _ int) error {
+	f, err := geojson.PolygonFeature(poly, pointCount, opt)
+	if err != nil {
+		return err
+	}
+	return json.NewEncoder(w).Encode(f)
+}
+
+func writeWKT(w io.Writer, poly concaveman.Polygon, _ int, _ concaveman.Options, _ int) error {
+	s, err := wkt.Polygon(poly)
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintln(w, s)
+	return err
+}
+
+func writeWKB(w io.Writer, poly concaveman.Polygon, _ int, _ concaveman.Options, srid int) error {
+	_, err := w.Write(wkb.Polygon(poly, srid))
+	return err
+}
+
+func writeWKBHex(w io.Writer, poly concaveman.Polygon, _ int, _ concaveman.Options, srid int) error {
+	_, err := fmt.Fprintln(w, hex.EncodeToString(wkb.Polygon(poly, srid)))
+	return err
+}
@@ -0,0 +1,76 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/wsw0108/concaveman-go/render"
+)
+
+func runRender(args []string, stdin io.Reader, stdout, stderr io.Writer) int {
+	flags := flag.NewFlagSet("concaveman render", flag.ContinueOnError)
+	flags.SetOutput(stderr)
+	hf := newHullFlags(flags)
+	output := flags.String("o", "", "output file; standard output if empty")
+	format := flags.String("format", "", "image format: svg or png; guessed from -o, svg by default")
+	width := flags.Int("width", 800, "image width in pixels")
+	height := flags.Int("height", 800, "image height in pixels")
+	flags.Usage = func() {
+		fmt.Fprintln(stderr, "usage: concaveman render [flags] [file ...]")
+		flags.PrintDefaults()
+	}
+	if err := flags.Parse(args); err != nil {
+		return exitUsage
+	}
+
+	if *format == "" {
+		*format = strings.TrimPrefix(strings.ToLower(filepath.Ext(*output)), ".")
+		if *format == "" {
+			*format = "svg"
+		}
+	}
+	var draw func(io.Writer, *render.Scene, int, int) error
+	switch *format {
+	case "svg":
+		draw = render.SVG
+	case "png":
+		draw = render.PNG
+	default:
+		fmt.Fprintf(stderr, "concaveman: unknown image format %q\n", *format)
+		return exitUsage
+	}
+	if *width < render.MinSize || *width > render.MaxSize || *height < render.MinSize || *height > render.MaxSize {
+		fmt.Fprintf(stderr, "concaveman: image size must be between %d and %d pixels\n", render.MinSize, render.MaxSize)
+		return exitUsage
+	}
+
+	points, code := hf.load(flags.Args(), stdin, stderr)
+	if code != exitOK {
+		return code
+	}
+	scene, err := render.Compute(points, hf.options())
+	if err != nil {
+		fmt.Fprintf(stderr, "concaveman: %v\n", err)
+		return exitDegenerate
+	}
+
+	w := stdout
+	if *output != "" {
+		f, err := os.Create(*output)
+		if err != nil {
+			fmt.Fprintf(stderr, "concaveman: %v\n", err)
+			return exitError
+		}
+		defer f.Close()
+		w = f
+	}
+	if err := draw(w, scene, *width, *height); err != nil {
+		fmt.Fprintf(stderr, "concaveman: %v\n", err)
+		return exitError
+	}
+	return exitOK
+}
@@ -1,10 +1,10 @@
 package concaveman
 
 import (
+	"context"
 	"math"
 	"sort"
 
-	"github.com/tidwall/tinyqueue"
 	"github.com/wsw0108/concaveman-go/predicates"
 	"github.com/wsw0108/concaveman-go/rbush"
 )
@@ -12,10 +12,47 @@ import (
 type Options struct {
 	Concavity       float64
 	LengthThreshold float64
+	// MaxIterations limits the number of edges processed; when it is reached
+	// the hull refined so far is returned. Zero means no limit.
+	MaxIterations int
+	// Observer, if set, is notified of the steps of the computation.
+	Observer Observer
+	// MinHoleArea enables holes in ConcavemanPolygon: empty regions inside
+	// the hull that can hold a point-free circle of this area become holes.
+	// Zero disables holes.
+	MinHoleArea float64
+	// Geodesic treats points as [longitude, latitude] in degrees and measures
+	// distances along great circles, so that LengthThreshold, MinHoleArea and
+	// the distance of ConcavemanMulti are in meters and square meters. Point
+	// sets crossing the antimeridian are handled. Hulls made with
+	// NewHullOptions honour it.
+	Geodesic bool
+	// Projection, if set, maps the points to the plane before computing the
+	// hull, so that lengths and areas are measured in projected units. It
+	// takes precedence over Geodesic. Hulls made with NewHullOptions honour
+	// it.
+	Projection Projection
+	// StaticIndex keeps the points in a packed Hilbert R-tree (see package
+	// flatbush) rather than a dynamic one, which is lighter to build and to
+	// query. Hulls made with NewHullOptions keep it until a point is added
+	// inside them.
+	StaticIndex bool
 }
 
+// Projection maps [longitude, latitude] points to the plane. The projections
+// of package proj implement it. The hull is made of input points, so no
+// inverse is needed to return it.
+type Projection interface {
+	Forward(lon, lat float64) (x, y float64)
+}
+
+// how many edges are processed, or candidate points visited, between two
+// checks of the context
+const ctxCheckInterval = 256
+
 type node struct {
 	p    Point
+	i    int
 	prev *node
 	next *node
 	minX float64
@@ -31,6 +68,19 @@ func (p Point) Rect() (min, max [2]float64) {
 	return
 }
 
+// a point along with its position in the input
+type indexedPoint struct {
+	p Point
+	i int
+}
+
+// impl rbush.Item
+func (ip indexedPoint) Rect() (min, max [2]float64) {
+	min = ip.p
+	max = ip.p
+	return
+}
+
 // impl rbush.Item
 func (n node) Rect() (min, max [2]float64) {
 	min = [2]float64{n.minX, n.minY}
@@ -40,21 +90,51 @@ func (n node) Rect() (min, max [2]float64) {
 
 var (
 	_ rbush.Item = Point{}
+	_ rbush.Item = indexedPoint{}
 	_ rbush.Item = node{}
 )
 
-type qnode struct {
-	node interface{}
-	dist float64
+// Concaveman computes a concave hull of the given points and returns it as a
+// closed ring. Degenerate input does not panic; it yields the fallback results
+// documented on ConcavemanE.
+func Concaveman(points []Point, opts ...Options) []Point {
+	hull, _ := ConcavemanE(points, opts...)
+	return hull
 }
 
-// impl tinyqueue.Item
-func (a qnode) Less(b tinyqueue.Item) bool {
-	// compareDist(a, b)
-	return a.dist < b.(*qnode).dist
+// ConcavemanE is like Concaveman but reports degenerate input. If the points
+// contain a NaN or infinite coordinate, fewer than 3 distinct points, or only
+// collinear points, it returns ErrNonFinite, ErrTooFewPoints or ErrCollinear
+// respectively, together with a fallback result:
+//
+//   - ErrNonFinite: nil.
+//   - ErrTooFewPoints: nil for no points, [p, p] for a single distinct point
+//     and [a, b, a] for two distinct points.
+//   - ErrCollinear: [a, b, a] where a and b are the extreme points of the line.
+func ConcavemanE(points []Point, opts ...Options) ([]Point, error) {
+	return ConcavemanContext(context.Background(), points, opts...)
 }
 
-func Concaveman(points []Point, opts ...Options) []Point {
+// ConcavemanContext is like ConcavemanE but stops early when ctx is done. In
+// that case it returns the partial hull refined so far together with
+// ctx.Err(), which is the convex hull if ctx is done before refinement
+// starts; a deadline on ctx thus acts as a time budget for the computation.
+// ctx is checked once the convex hull is found, every few hundred edges and
+// every few hundred candidate points visited for an edge.
+func ConcavemanContext(ctx context.Context, points []Point, opts ...Options) ([]Point, error) {
+	indices, err := concaveman(ctx, points, getOptions(opts))
+	return pick(points, indices), err
+}
+
+// ConcavemanIndices is like Concaveman but returns the hull as the positions
+// of its vertices in points, so that duplicate coordinates remain
+// distinguishable. The ring is closed, its last index repeating the first.
+func ConcavemanIndices(points []Point, opts ...Options) []int {
+	indices, _ := concaveman(context.Background(), points, getOptions(opts))
+	return indices
+}
+
+func getOptions(opts []Options) Options {
 	opt := Options{
 		Concavity:       2,
 		LengthThreshold: 0,
@@ -62,175 +142,145 @@ func Concaveman(points []Point, opts ...Options) []Point {
 	if len(opts) > 0 {
 		opt = opts[0]
 	}
+	return opt
+}
 
-	// a relative measure of concavity; higher value means simpler hull
-	concavity := math.Max(0, opt.Concavity)
-	// when a segment goes below this length threshold, it won't be drilled down further
-	lengthThreshold := opt.LengthThreshold
-
-	// start with a convex hull of the points
-	hull := fastConvexHull(points)
-
-	// index the points with an R-tree
-	tree := rbush.New(16)
-	items := make([]rbush.Item, 0, len(points))
-	for _, p := range points {
-		items = append(items, p)
+// concaveman computes the hull as indices into points
+func concaveman(ctx context.Context, points []Point, opt Options) ([]int, error) {
+	points, _ = planeOf(points, opt)
+	if indices, err := validate(points); err != nil {
+		return indices, err
 	}
-	tree.Load(items)
-
-	// turn the convex hull into a linked list and populate the initial edge queue with the nodes
-	queue := make([]*node, 0, len(hull))
-	var last *node
-	for _, p := range hull {
-		tree.Remove(p)
-		last = insertNode(p, last)
-		queue = append(queue, last)
+	ips := indexPoints(points)
+	hull, cull := fastConvexHull(ips)
+	if err := ctx.Err(); err != nil {
+		// stopped before loading the index, so the convex hull is all there is
+		indices := make([]int, len(hull)+1)
+		for i, ip := range hull {
+			indices[i] = ip.i
+		}
+		indices[len(hull)] = hull[0].i
+		return indices, err
 	}
-
-	// index the segments with an R-tree (for intersection checks)
-	// segTree := &rtree.RTreeG[*node]{}
-	segTree := rbush.New(16)
-	for _, n := range queue {
-		updateBBox(n)
-		// segTree.Insert([2]float64{n.minX, n.minY}, [2]float64{n.maxX, n.maxY}, n)
-		segTree.Insert(n)
+	h := newConvexHull(ips, hull, cull, len(ips), opt.StaticIndex)
+	if opt.Geodesic && opt.Projection == nil {
+		h.metric = sphere{}
 	}
+	err := h.refine(ctx, opt)
+	return h.Indices(), err
+}
 
-	sqConcavity := concavity * concavity
-	sqLenThreshold := lengthThreshold * lengthThreshold
-
-	// process edges one by one
-	for len(queue) > 0 {
-		node := queue[0]
-		queue = queue[1:]
-		a := node.p
-		b := node.next.p
-
-		// skip the edge if it's already short enough
-		sqLen := getSqDist(a, b)
-		if sqLen < sqLenThreshold {
-			continue
+// planeOf returns the points in the plane the hull is computed in with the
+// given options: projected, unwrapped for geodesic hulls, or as they are. It
+// also returns the mapping of further points to that plane, or nil if the
+// points are used as they are.
+func planeOf(points []Point, opt Options) ([]Point, func(p Point) Point) {
+	switch {
+	case opt.Projection != nil:
+		return project(points, opt.Projection), func(p Point) Point {
+			x, y := opt.Projection.Forward(p[0], p[1])
+			return Point{x, y}
 		}
-
-		maxSqLen := sqLen / sqConcavity
-
-		// find the best connection point for the current edge to flex inward to
-		p, ok := findCandidate(tree, node.prev.p, a, b, node.next.next.p, maxSqLen, segTree)
-
-		// if we found a connection and it satisfies our concavity measure
-		if ok && math.Min(getSqDist(p, a), getSqDist(p, b)) <= maxSqLen {
-			// connect the edge endpoints through this point and add 2 new edges to the queue
-			queue = append(queue, node)
-			queue = append(queue, insertNode(p, node))
-
-			// update point and segment indexes
-			tree.Remove(p)
-			// segTree.Delete([2]float64{node.minX, node.minY}, [2]float64{node.maxX, node.maxY}, node)
-			segTree.Remove(node)
-			n1 := updateBBox(node)
-			n2 := updateBBox(node.next)
-			// segTree.Insert([2]float64{n1.minX, n1.minY}, [2]float64{n1.maxX, n1.maxY}, n1)
-			// segTree.Insert([2]float64{n2.minX, n2.minY}, [2]float64{n2.maxX, n2.maxY}, n2)
-			segTree.Insert(n1)
-			segTree.Insert(n2)
+	case opt.Geodesic:
+		cut, ok := unwrapCut(points)
+		if !ok {
+			return points, nil
 		}
-	}
-
-	// convert the resulting hull linked list to an array of points
-	node := last
-	var concave []Point
-	for {
-		concave = append(concave, node.p)
-		node = node.next
-		if node == last {
-			break
+		return unwrap(points), func(p Point) Point {
+			return Point{unwrapLon(p[0], cut), p[1]}
 		}
 	}
+	return points, nil
+}
 
-	concave = append(concave, node.p)
+// project applies the projection to the points
+func project(points []Point, p Projection) []Point {
+	result := make([]Point, len(points))
+	for i, pt := range points {
+		result[i][0], result[i][1] = p.Forward(pt[0], pt[1])
+	}
+	return result
+}
 
-	return concave
+// pick returns the points at the given indices
+func pick(points []Point, indices []int) []Point {
+	if indices == nil {
+		return nil
+	}
+	result := make([]Point, len(indices))
+	for i, j := range indices {
+		result[i] = points[j]
+	}
+	return result
 }
 
 // func findCandidate(tree *rbush.RBush, a, b, c, d Point, maxDist float64, segTree *rtree.RTreeG[*node]) (Point, bool) {
-func findCandidate(tree *rbush.RBush, a, b, c, d Point, maxDist float64, segTree *rbush.RBush) (Point, bool) {
-	queue := tinyqueue.New(nil)
-	node := tree.Data
+func findCandidate(ctx context.Context, tree pointIndex, a, b, c, d Point, maxDist float64, segTree *rbush.Tree[*node], m metric, observer Observer) (indexedPoint, bool, error) {
+	bc := m.segment(b, c)
+	var ab, cd segment
+	var found indexedPoint
+	var ok bool
+	var err error
+	var visited int
 
 	// search through the point R-tree with a depth-first search using a priority queue
 	// in the order of distance to the edge (b, c)
-	for node != nil {
-		for _, child := range node.Children {
-			var dist float64
-			if node.Leaf {
-				dist = sqSegDist(child.(Point), b, c)
-			} else {
-				dist = sqSegBoxDist(b, c, child.(*rbush.TreeNode))
+	tree.NearestIter(bc.sqBoxDist, func(ip indexedPoint) float64 {
+		return bc.sqDist(ip.p)
+	}, maxDist, func(ip indexedPoint, dist float64) bool {
+		// a long edge over many rejected points can take a while on its own
+		visited++
+		if visited%ctxCheckInterval == 0 {
+			if err = ctx.Err(); err != nil {
+				return false
 			}
-			if dist > maxDist {
-				// skip the node if it's farther than we ever need
-				continue
-			}
-			queue.Push(&qnode{
-				node: child,
-				dist: dist,
-			})
 		}
+		p := ip.p
 
-		for queue.Len() > 0 {
-			{
-				item := queue.Peek()
-				qn := item.(*qnode)
-				if _, ok := qn.node.(Point); !ok {
-					break
-				}
-			}
-			item := queue.Pop()
-			qn := item.(*qnode)
-			p := qn.node.(Point)
-
-			// skip all points that are as close to adjacent edges (a,b) and (c,d),
-			// and points that would introduce self-intersections when connected
-			d0 := sqSegDist(p, a, b)
-			d1 := sqSegDist(p, c, d)
-			if qn.dist < d0 && qn.dist < d1 &&
-				noIntersections(b, p, segTree) &&
+		// skip all points that are as close to adjacent edges (a,b) and (c,d),
+		// and points that would introduce self-intersections when connected
+		if ab == nil {
+			ab = m.segment(a, b)
+			cd = m.segment(c, d)
+		}
+		d0 := ab.sqDist(p)
+		d1 := cd.sqDist(p)
+		if dist < d0 && dist < d1 {
+			if noIntersections(b, p, segTree) &&
 				noIntersections(c, p, segTree) {
-				return p, true
+				found, ok = ip, true
+				return false
 			}
+			if observer != nil {
+				observer.OnCandidateRejected(p, RejectIntersection)
+			}
+		} else if observer != nil {
+			observer.OnCandidateRejected(p, RejectAdjacentEdge)
 		}
+		return true
+	})
 
-		item := queue.Pop()
-		if item != nil {
-			qn := item.(*qnode)
-			node = qn.node.(*rbush.TreeNode)
-		} else {
-			node = nil
-		}
-	}
-
-	return Point{}, false
+	return found, ok, err
 }
 
 // square distance from a segment bounding box to the given one
-func sqSegBoxDist(a, b Point, bbox *rbush.TreeNode) float64 {
-	if inside(a, bbox) || inside(b, bbox) {
+func sqSegBoxDist(a, b Point, min, max [2]float64) float64 {
+	if inside(a, min, max) || inside(b, min, max) {
 		return 0
 	}
-	d1 := sqSegSegDist(a[0], a[1], b[0], b[1], bbox.Min[0], bbox.Min[1], bbox.Max[0], bbox.Min[1])
+	d1 := sqSegSegDist(a[0], a[1], b[0], b[1], min[0], min[1], max[0], min[1])
 	if d1 == 0 {
 		return 0
 	}
-	d2 := sqSegSegDist(a[0], a[1], b[0], b[1], bbox.Min[0], bbox.Min[1], bbox.Min[0], bbox.Max[1])
+	d2 := sqSegSegDist(a[0], a[1], b[0], b[1], min[0], min[1], min[0], max[1])
 	if d2 == 0 {
 		return 0
 	}
-	d3 := sqSegSegDist(a[0], a[1], b[0], b[1], bbox.Max[0], bbox.Min[1], bbox.Max[0], bbox.Max[1])
+	d3 := sqSegSegDist(a[0], a[1], b[0], b[1], max[0], min[1], max[0], max[1])
 	if d3 == 0 {
 		return 0
 	}
-	d4 := sqSegSegDist(a[0], a[1], b[0], b[1], bbox.Min[0], bbox.Max[1], bbox.Max[0], bbox.Max[1])
+	d4 := sqSegSegDist(a[0], a[1], b[0], b[1], min[0], max[1], max[0], max[1])
 	if d4 == 0 {
 		return 0
 	}
@@ -239,16 +289,16 @@ func sqSegBoxDist(a, b Point, bbox *rbush.TreeNode) float64 {
 	return math.Min(m1, m2)
 }
 
-func inside(a Point, bbox *rbush.TreeNode) bool {
-	return a[0] >= bbox.Min[0] &&
-		a[0] <= bbox.Max[0] &&
-		a[1] >= bbox.Min[1] &&
-		a[1] <= bbox.Max[1]
+func inside(a Point, min, max [2]float64) bool {
+	return a[0] >= min[0] &&
+		a[0] <= max[0] &&
+		a[1] >= min[1] &&
+		a[1] <= max[1]
 }
 
 // check if the edge (a,b) doesn't intersect any other edges
 // func noIntersections(a, b Point, segTree *rtree.RTreeG[*node]) bool {
-func noIntersections(a, b Point, segTree *rbush.RBush) bool {
+func noIntersections(a, b Point, segTree *rbush.Tree[*node]) bool {
 	minX := math.Min(a[0], b[0])
 	minY := math.Min(a[1], b[1])
 	maxX := math.Max(a[0], b[0])
@@ -261,14 +311,7 @@ func noIntersections(a, b Point, segTree *rbush.RBush) bool {
 	// 	return true
 	// })
 
-	s := node{
-		minX: minX,
-		minY: minY,
-		maxX: maxX,
-		maxY: maxY,
-	}
-	segTree.Search(s, func(item rbush.Item) bool {
-		edge := item.(*node)
+	segTree.Search([2]float64{minX, minY}, [2]float64{maxX, maxY}, func(edge *node) bool {
 		edges = append(edges, edge)
 		return true
 	})
@@ -304,46 +347,49 @@ func updateBBox(node *node) *node {
 	return node
 }
 
-// speed up convex hull by filtering out points inside quadrilateral formed by 4 extreme points
-func fastConvexHull(points []Point) []Point {
+// speed up convex hull by filtering out points inside quadrilateral formed by 4 extreme points;
+// the quadrilateral is returned as well
+func fastConvexHull(points []indexedPoint) ([]indexedPoint, []indexedPoint) {
 	left := points[0]
 	top := points[0]
 	right := points[0]
 	bottom := points[0]
 
 	// find the leftmost, rightmost, topmost and bottommost points
-	for _, p := range points {
-		if p[0] < left[0] {
-			left = p
+	for _, ip := range points {
+		p := ip.p
+		if p[0] < left.p[0] {
+			left = ip
 		}
-		if p[0] > right[0] {
-			right = p
+		if p[0] > right.p[0] {
+			right = ip
 		}
-		if p[1] < top[1] {
-			top = p
+		if p[1] < top.p[1] {
+			top = ip
 		}
-		if p[1] > bottom[1] {
-			bottom = p
+		if p[1] > bottom.p[1] {
+			bottom = ip
 		}
 	}
 
 	// filter out points that are inside the resulting quadrilateral
-	cull := []Point{left, top, right, bottom}
-	filtered := []Point{left, top, right, bottom}
-	for _, p := range points {
-		if !PointInPolygon(p, cull) {
-			filtered = append(filtered, p)
+	quad := []Point{left.p, top.p, right.p, bottom.p}
+	filtered := []indexedPoint{left, top, right, bottom}
+	for _, ip := range points {
+		if !PointInPolygon(ip.p, quad) {
+			filtered = append(filtered, ip)
 		}
 	}
 
 	// get convex hull around the filtered points
-	return convexHull(filtered)
+	return convexHull(filtered), []indexedPoint{left, top, right, bottom}
 }
 
 // create a new node in a doubly linked list
-func insertNode(p Point, prev *node) *node {
+func insertNode(ip indexedPoint, prev *node) *node {
 	node := &node{
-		p: p,
+		p: ip.p,
+		i: ip.i,
 	}
 
 	if prev == nil {
@@ -475,7 +521,7 @@ func sqSegDist(p, p1, p2 Point) float64 {
 	return dx*dx + dy*dy
 }
 
-type compareByX []Point
+type compareByX []indexedPoint
 
 func (ps compareByX) Len() int {
 	return len(ps)
@@ -486,38 +532,38 @@ func f64Less(a, b float64) bool {
 }
 
 func (ps compareByX) Less(i, j int) bool {
-	if ps[i][0] == ps[j][0] {
-		return f64Less(ps[i][1], ps[j][1])
+	if ps[i].p[0] == ps[j].p[0] {
+		return f64Less(ps[i].p[1], ps[j].p[1])
 	}
-	return f64Less(ps[i][0], ps[j][0])
+	return f64Less(ps[i].p[0], ps[j].p[0])
 }
 
 func (ps compareByX) Swap(i, j int) {
 	ps[i], ps[j] = ps[j], ps[i]
 }
 
-func convexHull(points []Point) []Point {
+func convexHull(points []indexedPoint) []indexedPoint {
 	sort.Sort(compareByX(points))
 
-	var lower []Point
+	var lower []indexedPoint
 	for i := range points {
 		p := points[i]
-		for len(lower) >= 2 && cross(lower[len(lower)-2], lower[len(lower)-1], p) <= 0 {
+		for len(lower) >= 2 && cross(lower[len(lower)-2].p, lower[len(lower)-1].p, p.p) <= 0 {
 			lower = lower[:len(lower)-1]
 		}
 		lower = append(lower, p)
 	}
 
-	var upper []Point
+	var upper []indexedPoint
 	for i := range points {
 		p := points[len(points)-i-1]
-		for len(upper) >= 2 && cross(upper[len(upper)-2], upper[len(upper)-1], p) <= 0 {
+		for len(upper) >= 2 && cross(upper[len(upper)-2].p, upper[len(upper)-1].p, p.p) <= 0 {
 			upper = upper[:len(upper)-1]
 		}
 		upper = append(upper, p)
 	}
 
-	result := make([]Point, 0, len(lower)-1+len(upper)-1)
+	result := make([]indexedPoint, 0, len(lower)-1+len(upper)-1)
 	for i := range lower {
 		if i == len(lower)-1 {
 			break
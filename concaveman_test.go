@@ -1,7 +1,10 @@
 package concaveman_test
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
+	"math"
 	"os"
 	"reflect"
 	"testing"
@@ -83,3 +86,140 @@ func TestTunedConcaveHull(t *testing.T) {
 		t.Error("TestTunedConcaveHull")
 	}
 }
+
+func TestDegenerateConcaveHull(t *testing.T) {
+	nan := math.NaN()
+	tests := []struct {
+		name   string
+		points []concaveman.Point
+		want   []concaveman.Point
+		err    error
+	}{
+		{
+			name:   "empty",
+			points: nil,
+			want:   nil,
+			err:    concaveman.ErrTooFewPoints,
+		},
+		{
+			name:   "single",
+			points: []concaveman.Point{{1, 2}},
+			want:   []concaveman.Point{{1, 2}, {1, 2}},
+			err:    concaveman.ErrTooFewPoints,
+		},
+		{
+			name:   "duplicates",
+			points: []concaveman.Point{{1, 2}, {1, 2}, {1, 2}},
+			want:   []concaveman.Point{{1, 2}, {1, 2}},
+			err:    concaveman.ErrTooFewPoints,
+		},
+		{
+			name:   "two",
+			points: []concaveman.Point{{1, 2}, {3, 4}, {1, 2}},
+			want:   []concaveman.Point{{1, 2}, {3, 4}, {1, 2}},
+			err:    concaveman.ErrTooFewPoints,
+		},
+		{
+			name:   "collinear",
+			points: []concaveman.Point{{1, 1}, {0, 0}, {3, 3}, {2, 2}},
+			want:   []concaveman.Point{{0, 0}, {3, 3}, {0, 0}},
+			err:    concaveman.ErrCollinear,
+		},
+		{
+			name:   "nan",
+			points: []concaveman.Point{{0, 0}, {1, 0}, {nan, 1}},
+			want:   nil,
+			err:    concaveman.ErrNonFinite,
+		},
+		{
+			name:   "inf",
+			points: []concaveman.Point{{0, 0}, {1, math.Inf(1)}, {0, 1}},
+			want:   nil,
+			err:    concaveman.ErrNonFinite,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := concaveman.ConcavemanE(tt.points)
+			if !errors.Is(err, tt.err) {
+				t.Errorf("ConcavemanE() error = %v, want %v", err, tt.err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ConcavemanE() = %v, want %v", got, tt.want)
+			}
+			if got := concaveman.Concaveman(tt.points); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Concaveman() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestConcaveHullContext(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	result, err := concaveman.ConcavemanContext(ctx, g_points)
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("TestConcaveHullContext: error = %v", err)
+	}
+	h, _ := concaveman.NewHull(g_points)
+	if !reflect.DeepEqual(result, h.ConvexHull()) {
+		t.Errorf("TestConcaveHullContext: got %v, want the convex hull", result)
+	}
+
+	result, err = concaveman.ConcavemanContext(context.Background(), g_points)
+	if err != nil || !reflect.DeepEqual(result, g_hull) {
+		t.Error("TestConcaveHullContext")
+	}
+}
+
+func TestMaxIterations(t *testing.T) {
+	full := concaveman.Concaveman(g_points)
+	prev := 0
+	for _, n := range []int{1, 10, 100} {
+		result := concaveman.Concaveman(g_points, concaveman.Options{
+			Concavity:     2,
+			MaxIterations: n,
+		})
+		if len(result) < prev || len(result) >= len(full) {
+			t.Errorf("TestMaxIterations: %d iterations gave %d points, the full hull has %d", n, len(result), len(full))
+		}
+		if result[0] != result[len(result)-1] {
+			t.Errorf("TestMaxIterations: %d iterations gave an open ring", n)
+		}
+		prev = len(result)
+	}
+}
+
+func TestConcaveHullIndices(t *testing.T) {
+	indices := concaveman.ConcavemanIndices(g_points)
+	if len(indices) != len(g_hull) {
+		t.Fatalf("TestConcaveHullIndices: got %d indices, want %d", len(indices), len(g_hull))
+	}
+	for i, j := range indices {
+		if g_points[j] != g_hull[i] {
+			t.Errorf("TestConcaveHullIndices: index %d", i)
+		}
+	}
+
+	// duplicates resolve to distinct positions
+	points := []concaveman.Point{
+		{0, 0},
+		{2, 0},
+		{1, 2},
+		{1, 1},
+		{0, 0},
+	}
+	indices = concaveman.ConcavemanIndices(points)
+	seen := make(map[int]bool)
+	for _, j := range indices[:len(indices)-1] {
+		if seen[j] {
+			t.Errorf("TestConcaveHullIndices: index %d repeated", j)
+		}
+		seen[j] = true
+	}
+
+	indices = concaveman.ConcavemanIndices([]concaveman.Point{{1, 1}, {0, 0}, {3, 3}, {2, 2}})
+	if !reflect.DeepEqual(indices, []int{1, 2, 1}) {
+		t.Errorf("TestConcaveHullIndices: collinear = %v", indices)
+	}
+}
@@ -0,0 +1,78 @@
+package concaveman
+
+import (
+	"errors"
+	"fmt"
+	"math"
+)
+
+var (
+	// ErrTooFewPoints is returned when the input has fewer than 3 distinct points.
+	ErrTooFewPoints = errors.New("concaveman: too few distinct points")
+	// ErrCollinear is returned when all input points lie on a single line.
+	ErrCollinear = errors.New("concaveman: all points are collinear")
+	// ErrNonFinite is returned when a point has a NaN or infinite coordinate.
+	ErrNonFinite = errors.New("concaveman: non-finite coordinate")
+)
+
+func isFinite(f float64) bool {
+	return !math.IsNaN(f) && !math.IsInf(f, 0)
+}
+
+// validate checks that a hull can be computed for the points, and otherwise
+// returns the fallback result for the degenerate input, as indices into
+// points, along with its error
+func validate(points []Point) ([]int, error) {
+	for i, p := range points {
+		if !isFinite(p[0]) || !isFinite(p[1]) {
+			return nil, fmt.Errorf("%w at index %d", ErrNonFinite, i)
+		}
+	}
+
+	if len(points) == 0 {
+		return nil, ErrTooFewPoints
+	}
+
+	// find two distinct points
+	a := 0
+	b := -1
+	for i, p := range points {
+		if p != points[a] {
+			b = i
+			break
+		}
+	}
+	if b < 0 {
+		return []int{a, a}, ErrTooFewPoints
+	}
+
+	// look for a point off the line (a,b), tracking the extremes along the way
+	min, max := a, a
+	distinct := false
+	for i, p := range points {
+		if cross(points[a], points[b], p) != 0 {
+			return nil, nil
+		}
+		if p != points[a] && p != points[b] {
+			distinct = true
+		}
+		if pointLess(p, points[min]) {
+			min = i
+		}
+		if pointLess(points[max], p) {
+			max = i
+		}
+	}
+	if !distinct {
+		return []int{a, b, a}, ErrTooFewPoints
+	}
+	return []int{min, max, min}, ErrCollinear
+}
+
+// lexicographic order on (x, y)
+func pointLess(a, b Point) bool {
+	if a[0] == b[0] {
+		return a[1] < b[1]
+	}
+	return a[0] < b[0]
+}
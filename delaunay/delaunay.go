@@ -0,0 +1,530 @@
+// Package delaunay computes Delaunay triangulations of points in the plane,
+// ported from mapbox's JS delaunator, along with their Voronoi diagrams.
+// Orientation and incircle tests use the exact predicates of package
+// predicates.
+package delaunay
+
+import (
+	"math"
+
+	"github.com/wsw0108/concaveman-go/predicates"
+)
+
+// Triangulation is a Delaunay triangulation of a point set. Triangle t is
+// formed by the points Triangles[3*t], Triangles[3*t+1] and Triangles[3*t+2],
+// given as indices into Points, and Halfedges[e] is the index of the halfedge
+// opposite to e, or -1 on the convex hull. Hull lists the points of the convex
+// hull, without repeating the first one. Triangles and hull wind clockwise
+// with the y axis pointing up. Of duplicate points only one is
+// triangulated; if all points are collinear, there are no triangles and Hull
+// lists the points along the line.
+type Triangulation[P ~[2]float64] struct {
+	Points    []P
+	Triangles []int
+	Halfedges []int
+	Hull      []int
+
+	// state used while building
+	hullPrev     []int
+	hullNext     []int
+	hullTri      []int
+	hullHash     []int
+	hashSize     int
+	hullStart    int
+	cx, cy       float64
+	trianglesLen int
+	edgeStack    [512]int
+}
+
+var delaunayEpsilon = math.Pow(2, -52)
+
+// New triangulates the points.
+func New[P ~[2]float64](points []P) *Triangulation[P] {
+	n := len(points)
+	maxTriangles := 2*n - 5
+	if maxTriangles < 0 {
+		maxTriangles = 0
+	}
+	t := &Triangulation[P]{
+		Points:    points,
+		Triangles: make([]int, maxTriangles*3),
+		Halfedges: make([]int, maxTriangles*3),
+		hashSize:  int(math.Ceil(math.Sqrt(float64(n)))),
+		hullPrev:  make([]int, n),
+		hullNext:  make([]int, n),
+		hullTri:   make([]int, n),
+	}
+	t.hullHash = make([]int, t.hashSize)
+	if n > 0 {
+		t.update()
+	}
+	// drop the building state
+	t.hullPrev, t.hullNext, t.hullTri, t.hullHash = nil, nil, nil, nil
+	return t
+}
+
+func (t *Triangulation[P]) update() {
+	points := t.Points
+	n := len(points)
+
+	// populate an array of point indices; calculate input data bbox
+	minX := math.Inf(+1)
+	minY := math.Inf(+1)
+	maxX := math.Inf(-1)
+	maxY := math.Inf(-1)
+	ids := make([]int, n)
+	for i, p := range points {
+		minX = math.Min(minX, p[0])
+		minY = math.Min(minY, p[1])
+		maxX = math.Max(maxX, p[0])
+		maxY = math.Max(maxY, p[1])
+		ids[i] = i
+	}
+	cx := (minX + maxX) / 2
+	cy := (minY + maxY) / 2
+
+	var i0, i1, i2 int
+
+	// pick a seed point close to the center
+	minDist := math.Inf(+1)
+	for i, p := range points {
+		d := dist(cx, cy, p[0], p[1])
+		if d < minDist {
+			i0 = i
+			minDist = d
+		}
+	}
+	i0x, i0y := points[i0][0], points[i0][1]
+
+	// find the point closest to the seed
+	minDist = math.Inf(+1)
+	for i, p := range points {
+		if i == i0 {
+			continue
+		}
+		d := dist(i0x, i0y, p[0], p[1])
+		if d < minDist && d > 0 {
+			i1 = i
+			minDist = d
+		}
+	}
+	i1x, i1y := points[i1][0], points[i1][1]
+
+	// find the third point which forms the smallest circumcircle with the first two
+	minRadius := math.Inf(+1)
+	for i, p := range points {
+		if i == i0 || i == i1 {
+			continue
+		}
+		r := circumradius(i0x, i0y, i1x, i1y, p[0], p[1])
+		if r < minRadius {
+			i2 = i
+			minRadius = r
+		}
+	}
+	i2x, i2y := points[i2][0], points[i2][1]
+
+	dists := make([]float64, n)
+
+	if math.IsInf(minRadius, +1) {
+		// order collinear points by dx (or dy if all x are identical)
+		// and return the list as a hull
+		for i, p := range points {
+			dists[i] = p[0] - points[0][0]
+			if dists[i] == 0 {
+				dists[i] = p[1] - points[0][1]
+			}
+		}
+		quicksort(ids, dists, 0, n-1)
+		d0 := math.Inf(-1)
+		for _, id := range ids {
+			if d := dists[id]; d > d0 {
+				t.Hull = append(t.Hull, id)
+				d0 = d
+			}
+		}
+		t.Triangles = t.Triangles[:0]
+		t.Halfedges = t.Halfedges[:0]
+		return
+	}
+
+	// swap the order of the seed points for counter-clockwise orientation
+	if predicates.Orient2D(i0x, i0y, i1x, i1y, i2x, i2y) < 0 {
+		i1, i2 = i2, i1
+		i1x, i2x = i2x, i1x
+		i1y, i2y = i2y, i1y
+	}
+
+	t.cx, t.cy = circumcenter(i0x, i0y, i1x, i1y, i2x, i2y)
+
+	for i, p := range points {
+		dists[i] = dist(p[0], p[1], t.cx, t.cy)
+	}
+
+	// sort the points by distance from the seed triangle circumcenter
+	quicksort(ids, dists, 0, n-1)
+
+	// set up the seed triangle as the starting hull
+	t.hullStart = i0
+	hullSize := 3
+
+	hullNext := t.hullNext
+	hullPrev := t.hullPrev
+	hullTri := t.hullTri
+	hullHash := t.hullHash
+
+	hullNext[i0] = i1
+	hullPrev[i2] = i1
+	hullNext[i1] = i2
+	hullPrev[i0] = i2
+	hullNext[i2] = i0
+	hullPrev[i1] = i0
+
+	hullTri[i0] = 0
+	hullTri[i1] = 1
+	hullTri[i2] = 2
+
+	for i := range hullHash {
+		hullHash[i] = -1
+	}
+	hullHash[t.hashKey(i0x, i0y)] = i0
+	hullHash[t.hashKey(i1x, i1y)] = i1
+	hullHash[t.hashKey(i2x, i2y)] = i2
+
+	t.trianglesLen = 0
+	t.addTriangle(i0, i1, i2, -1, -1, -1)
+
+	var xp, yp float64
+	for k, i := range ids {
+		x, y := points[i][0], points[i][1]
+
+		// skip near-duplicate points
+		if k > 0 && math.Abs(x-xp) <= delaunayEpsilon && math.Abs(y-yp) <= delaunayEpsilon {
+			continue
+		}
+		xp = x
+		yp = y
+
+		// skip seed triangle points
+		if i == i0 || i == i1 || i == i2 {
+			continue
+		}
+
+		// find a visible edge on the convex hull using edge hash
+		start := 0
+		key := t.hashKey(x, y)
+		for j := 0; j < t.hashSize; j++ {
+			start = hullHash[(key+j)%t.hashSize]
+			if start != -1 && start != hullNext[start] {
+				break
+			}
+		}
+
+		start = hullPrev[start]
+		e := start
+		for {
+			q := hullNext[e]
+			if predicates.Orient2D(x, y, points[e][0], points[e][1], points[q][0], points[q][1]) < 0 {
+				break
+			}
+			e = q
+			if e == start {
+				e = -1
+				break
+			}
+		}
+		if e == -1 {
+			// likely a near-duplicate point; skip it
+			continue
+		}
+
+		// add the first triangle from the point
+		tr := t.addTriangle(e, i, hullNext[e], -1, -1, hullTri[e])
+
+		// recursively flip triangles from the point until they satisfy the Delaunay condition
+		hullTri[i] = t.legalize(tr + 2)
+		// keep track of boundary triangles on the hull
+		hullTri[e] = tr
+		hullSize++
+
+		// walk forward through the hull, adding more triangles and flipping recursively
+		n := hullNext[e]
+		for {
+			q := hullNext[n]
+			if predicates.Orient2D(x, y, points[n][0], points[n][1], points[q][0], points[q][1]) >= 0 {
+				break
+			}
+			tr = t.addTriangle(n, i, q, hullTri[i], -1, hullTri[n])
+			hullTri[i] = t.legalize(tr + 2)
+			// mark as removed
+			hullNext[n] = n
+			hullSize--
+			n = q
+		}
+
+		// walk backward from the other side, adding more triangles and flipping
+		if e == start {
+			for {
+				q := hullPrev[e]
+				if predicates.Orient2D(x, y, points[q][0], points[q][1], points[e][0], points[e][1]) >= 0 {
+					break
+				}
+				tr = t.addTriangle(q, i, e, -1, hullTri[e], hullTri[q])
+				t.legalize(tr + 2)
+				hullTri[q] = tr
+				// mark as removed
+				hullNext[e] = e
+				hullSize--
+				e = q
+			}
+		}
+
+		// update the hull indices
+		t.hullStart = e
+		hullPrev[i] = e
+		hullNext[e] = i
+		hullPrev[n] = i
+		hullNext[i] = n
+
+		// save the two new edges in the hash table
+		hullHash[t.hashKey(x, y)] = i
+		hullHash[t.hashKey(points[e][0], points[e][1])] = e
+	}
+
+	t.Hull = make([]int, hullSize)
+	e := t.hullStart
+	for i := 0; i < hullSize; i++ {
+		t.Hull[i] = e
+		e = hullNext[e]
+	}
+
+	// trim typed triangle mesh arrays
+	t.Triangles = t.Triangles[:t.trianglesLen]
+	t.Halfedges = t.Halfedges[:t.trianglesLen]
+}
+
+func (t *Triangulation[P]) hashKey(x, y float64) int {
+	return int(math.Floor(pseudoAngle(x-t.cx, y-t.cy)*float64(t.hashSize))) % t.hashSize
+}
+
+func (t *Triangulation[P]) legalize(a int) int {
+	i := 0
+	var ar int
+
+	// recursion eliminated with a fixed-size stack
+	for {
+		b := t.Halfedges[a]
+
+		/* if the pair of triangles doesn't satisfy the Delaunay condition
+		 * (p1 is inside the circumcircle of [p0, pl, pr]), flip them,
+		 * then do the same check/flip recursively for the new pair of triangles
+		 *
+		 *           pl                    pl
+		 *          /||\                  /  \
+		 *       al/ || \bl            al/    \a
+		 *        /  ||  \              /      \
+		 *       /  a||b  \    flip    /___ar___\
+		 *     p0\   ||   /p1   =>   p0\---bl---/p1
+		 *        \  ||  /              \      /
+		 *       ar\ || /br             b\    /br
+		 *          \||/                  \  /
+		 *           pr                    pr
+		 */
+		a0 := a - a%3
+		ar = a0 + (a+2)%3
+
+		if b == -1 { // convex hull edge
+			if i == 0 {
+				break
+			}
+			i--
+			a = t.edgeStack[i]
+			continue
+		}
+
+		b0 := b - b%3
+		al := a0 + (a+1)%3
+		bl := b0 + (b+2)%3
+
+		p0 := t.Points[t.Triangles[ar]]
+		pr := t.Points[t.Triangles[a]]
+		pl := t.Points[t.Triangles[al]]
+		p1 := t.Points[t.Triangles[bl]]
+
+		// triangles are clockwise with the y axis up, so InCircle is negative
+		// when p1 lies inside the circumcircle of (p0, pr, pl)
+		illegal := predicates.InCircle(p0[0], p0[1], pr[0], pr[1], pl[0], pl[1], p1[0], p1[1]) < 0
+
+		if illegal {
+			t.Triangles[a] = t.Triangles[bl]
+			t.Triangles[b] = t.Triangles[ar]
+
+			hbl := t.Halfedges[bl]
+
+			// edge swapped on the other side of the hull (rare); fix the halfedge reference
+			if hbl == -1 {
+				e := t.hullStart
+				for {
+					if t.hullTri[e] == bl {
+						t.hullTri[e] = a
+						break
+					}
+					e = t.hullPrev[e]
+					if e == t.hullStart {
+						break
+					}
+				}
+			}
+			t.link(a, hbl)
+			t.link(b, t.Halfedges[ar])
+			t.link(ar, bl)
+
+			br := b0 + (b+1)%3
+
+			// don't worry about hitting the cap: it can only happen on extremely degenerate input
+			if i < len(t.edgeStack) {
+				t.edgeStack[i] = br
+				i++
+			}
+		} else {
+			if i == 0 {
+				break
+			}
+			i--
+			a = t.edgeStack[i]
+		}
+	}
+
+	return ar
+}
+
+func (t *Triangulation[P]) link(a, b int) {
+	t.Halfedges[a] = b
+	if b != -1 {
+		t.Halfedges[b] = a
+	}
+}
+
+// add a new triangle given vertex indices and adjacent half-edge ids
+func (t *Triangulation[P]) addTriangle(i0, i1, i2, a, b, c int) int {
+	tr := t.trianglesLen
+
+	t.Triangles[tr] = i0
+	t.Triangles[tr+1] = i1
+	t.Triangles[tr+2] = i2
+
+	t.link(tr, a)
+	t.link(tr+1, b)
+	t.link(tr+2, c)
+
+	t.trianglesLen += 3
+
+	return tr
+}
+
+// monotonically increases with real angle, but doesn't need expensive trigonometry
+func pseudoAngle(dx, dy float64) float64 {
+	p := dx / (math.Abs(dx) + math.Abs(dy))
+	if dy > 0 {
+		return (3 - p) / 4 // [0..1]
+	}
+	return (1 + p) / 4 // [0..1]
+}
+
+func dist(ax, ay, bx, by float64) float64 {
+	dx := ax - bx
+	dy := ay - by
+	return dx*dx + dy*dy
+}
+
+func circumradius(ax, ay, bx, by, cx, cy float64) float64 {
+	dx := bx - ax
+	dy := by - ay
+	ex := cx - ax
+	ey := cy - ay
+
+	bl := dx*dx + dy*dy
+	cl := ex*ex + ey*ey
+	d := 0.5 / (dx*ey - dy*ex)
+
+	x := (ey*bl - dy*cl) * d
+	y := (dx*cl - ex*bl) * d
+
+	return x*x + y*y
+}
+
+func circumcenter(ax, ay, bx, by, cx, cy float64) (float64, float64) {
+	dx := bx - ax
+	dy := by - ay
+	ex := cx - ax
+	ey := cy - ay
+
+	bl := dx*dx + dy*dy
+	cl := ex*ex + ey*ey
+	d := 0.5 / (dx*ey - dy*ex)
+
+	x := ax + (ey*bl-dy*cl)*d
+	y := ay + (dx*cl-ex*bl)*d
+
+	return x, y
+}
+
+func quicksort(ids []int, dists []float64, left, right int) {
+	if right-left <= 20 {
+		for i := left + 1; i <= right; i++ {
+			temp := ids[i]
+			tempDist := dists[temp]
+			j := i - 1
+			for j >= left && dists[ids[j]] > tempDist {
+				ids[j+1] = ids[j]
+				j--
+			}
+			ids[j+1] = temp
+		}
+	} else {
+		median := (left + right) >> 1
+		i := left + 1
+		j := right
+		ids[median], ids[i] = ids[i], ids[median]
+		if dists[ids[left]] > dists[ids[right]] {
+			ids[left], ids[right] = ids[right], ids[left]
+		}
+		if dists[ids[i]] > dists[ids[right]] {
+			ids[i], ids[right] = ids[right], ids[i]
+		}
+		if dists[ids[left]] > dists[ids[i]] {
+			ids[left], ids[i] = ids[i], ids[left]
+		}
+
+		temp := ids[i]
+		tempDist := dists[temp]
+		for {
+			for {
+				i++
+				if dists[ids[i]] >= tempDist {
+					break
+				}
+			}
+			for {
+				j--
+				if dists[ids[j]] <= tempDist {
+					break
+				}
+			}
+			if j < i {
+				break
+			}
+			ids[i], ids[j] = ids[j], ids[i]
+		}
+		ids[left+1] = ids[j]
+		ids[j] = temp
+
+		if right-i+1 >= j-left {
+			quicksort(ids, dists, i, right)
+			quicksort(ids, dists, left, j-1)
+		} else {
+			quicksort(ids, dists, left, j-1)
+			quicksort(ids, dists, i, right)
+		}
+	}
+}
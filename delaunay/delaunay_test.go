@@ -0,0 +1,202 @@
+package delaunay_test
+
+import (
+	"encoding/json"
+	"math"
+	"math/rand"
+	"os"
+	"testing"
+
+	"github.com/wsw0108/concaveman-go/delaunay"
+	"github.com/wsw0108/concaveman-go/internal/testutil"
+	"github.com/wsw0108/concaveman-go/predicates"
+)
+
+type xy [2]float64
+
+func loadPoints(t *testing.T, filename string) []xy {
+	f, err := os.Open(filename)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	var points []xy
+	if err := json.NewDecoder(f).Decode(&points); err != nil {
+		t.Fatal(err)
+	}
+	return points
+}
+
+func randomPoints(n int) []xy {
+	return testutil.Points[xy](rand.New(rand.NewSource(42)), n, 1000)
+}
+
+func grid(n int) []xy {
+	var points []xy
+	for x := 0; x < n; x++ {
+		for y := 0; y < n; y++ {
+			points = append(points, xy{float64(x), float64(y)})
+		}
+	}
+	return points
+}
+
+func area(ring []xy) float64 {
+	var sum float64
+	for i := range ring {
+		j := (i + 1) % len(ring)
+		sum += ring[i][0]*ring[j][1] - ring[j][0]*ring[i][1]
+	}
+	return sum / 2
+}
+
+// validate checks the structure of the triangulation and that the triangles
+// exactly cover the convex hull, all winding clockwise
+func validate(t *testing.T, name string, points []xy) *delaunay.Triangulation[xy] {
+	d := delaunay.New(points)
+
+	for e, o := range d.Halfedges {
+		if o == -1 {
+			continue
+		}
+		if d.Halfedges[o] != e {
+			t.Fatalf("%s: halfedge %d is not linked back", name, e)
+		}
+		if d.Triangles[e] != d.Triangles[delaunay.NextHalfedge(o)] ||
+			d.Triangles[o] != d.Triangles[delaunay.NextHalfedge(e)] {
+			t.Fatalf("%s: halfedges %d and %d don't join the same points", name, e, o)
+		}
+	}
+
+	hull := make([]xy, len(d.Hull))
+	for i, p := range d.Hull {
+		hull[i] = points[p]
+	}
+	for i := range hull {
+		a, b, c := hull[i], hull[(i+1)%len(hull)], hull[(i+2)%len(hull)]
+		// Orient2D is positive for clockwise turns
+		if predicates.Orient2D(a[0], a[1], b[0], b[1], c[0], c[1]) < 0 {
+			t.Fatalf("%s: hull is not convex at %v", name, b)
+		}
+	}
+	hullArea := area(hull)
+
+	var sum float64
+	for i := 0; i < d.Len(); i++ {
+		tr := d.Triangle(i)
+		a := area(tr[:])
+		if a >= 0 {
+			t.Fatalf("%s: triangle %d winds the wrong way", name, i)
+		}
+		sum += a
+	}
+	if math.Abs(sum-hullArea) > 1e-9*math.Abs(hullArea) {
+		t.Errorf("%s: triangles cover %v, hull %v", name, sum, hullArea)
+	}
+	return d
+}
+
+func TestTriangulation(t *testing.T) {
+	tests := []struct {
+		name   string
+		points []xy
+	}{
+		{"points-1k", loadPoints(t, "../testdata/points-1k.json")},
+		{"random", randomPoints(2000)},
+		{"grid", grid(20)},
+		{"duplicates", append(grid(5), grid(5)...)},
+		{"triangle", []xy{{0, 0}, {1, 0}, {0, 1}}},
+	}
+	for _, tt := range tests {
+		validate(t, tt.name, tt.points)
+	}
+}
+
+func TestDelaunayCondition(t *testing.T) {
+	points := randomPoints(1000)
+	d := validate(t, "random", points)
+	if got, want := d.Len(), 2*len(points)-2-len(d.Hull); got != want {
+		t.Errorf("got %d triangles, want %d", got, want)
+	}
+	for e, o := range d.Halfedges {
+		if o == -1 {
+			continue
+		}
+		// the point opposite to e must not be inside the circumcircle of e's triangle
+		tr := delaunay.TriangleOfEdge(e)
+		c := d.Circumcenter(tr)
+		p := points[d.Triangles[delaunay.PrevHalfedge(o)]]
+		if dist := math.Hypot(p[0]-c[0], p[1]-c[1]); dist < d.Circumradius(tr)*(1-1e-9) {
+			t.Fatalf("point %v is inside the circumcircle of triangle %d", p, tr)
+		}
+	}
+}
+
+func TestCollinear(t *testing.T) {
+	points := []xy{{2, 2}, {0, 0}, {3, 3}, {1, 1}}
+	d := delaunay.New(points)
+	if d.Len() != 0 {
+		t.Errorf("TestCollinear: got %d triangles", d.Len())
+	}
+	want := []int{1, 3, 0, 2}
+	if len(d.Hull) != len(want) {
+		t.Fatalf("TestCollinear: hull = %v, want %v", d.Hull, want)
+	}
+	for i := range want {
+		if d.Hull[i] != want[i] {
+			t.Fatalf("TestCollinear: hull = %v, want %v", d.Hull, want)
+		}
+	}
+
+	if d := delaunay.New([]xy(nil)); d.Len() != 0 || len(d.Hull) != 0 {
+		t.Errorf("TestCollinear: empty input gave %v", d)
+	}
+}
+
+func TestVoronoi(t *testing.T) {
+	points := randomPoints(300)
+	d := delaunay.New(points)
+	onHull := make(map[int]bool)
+	for _, p := range d.Hull {
+		onHull[p] = true
+	}
+
+	cells := d.Voronoi()
+	if len(cells) != len(points) {
+		t.Fatalf("TestVoronoi: got %d cells", len(cells))
+	}
+	for i, cell := range cells {
+		if cell.Bounded == onHull[i] {
+			t.Errorf("TestVoronoi: cell %d bounded = %v", i, cell.Bounded)
+		}
+		if len(cell.Vertices) == 0 {
+			t.Fatalf("TestVoronoi: cell %d is empty", i)
+		}
+		// every vertex is as close to the site as to any other point
+		site := points[i]
+		for _, v := range cell.Vertices {
+			r := math.Hypot(v[0]-site[0], v[1]-site[1])
+			for _, p := range points {
+				if math.Hypot(v[0]-p[0], v[1]-p[1]) < r*(1-1e-9) {
+					t.Fatalf("TestVoronoi: vertex %v of cell %d is closer to %v", v, i, p)
+				}
+			}
+		}
+		if cell.Bounded && area(cell.Vertices) <= 0 {
+			t.Errorf("TestVoronoi: cell %d winds the wrong way", i)
+		}
+	}
+}
+
+func TestVoronoiDuplicates(t *testing.T) {
+	d := delaunay.New([]xy{{0, 0}, {1, 0}, {0, 1}, {1, 0}})
+	empty := 0
+	for _, cell := range d.Voronoi() {
+		if len(cell.Vertices) == 0 {
+			empty++
+		}
+	}
+	if empty != 1 {
+		t.Errorf("TestVoronoiDuplicates: got %d empty cells, want 1", empty)
+	}
+}
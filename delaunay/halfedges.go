@@ -0,0 +1,94 @@
+package delaunay
+
+import (
+	"math"
+)
+
+// NextHalfedge returns the halfedge following e in its triangle.
+func NextHalfedge(e int) int {
+	if e%3 == 2 {
+		return e - 2
+	}
+	return e + 1
+}
+
+// PrevHalfedge returns the halfedge preceding e in its triangle.
+func PrevHalfedge(e int) int {
+	if e%3 == 0 {
+		return e + 2
+	}
+	return e - 1
+}
+
+// TriangleOfEdge returns the triangle that the halfedge e belongs to.
+func TriangleOfEdge(e int) int {
+	return e / 3
+}
+
+// EdgesOfTriangle returns the three halfedges of the triangle t.
+func EdgesOfTriangle(t int) [3]int {
+	return [3]int{3 * t, 3*t + 1, 3*t + 2}
+}
+
+// Len returns the number of triangles.
+func (t *Triangulation[P]) Len() int {
+	return len(t.Triangles) / 3
+}
+
+// Triangle returns the points of the triangle tr.
+func (t *Triangulation[P]) Triangle(tr int) [3]P {
+	return [3]P{
+		t.Points[t.Triangles[3*tr]],
+		t.Points[t.Triangles[3*tr+1]],
+		t.Points[t.Triangles[3*tr+2]],
+	}
+}
+
+// Circumcenter returns the center of the circle through the points of the
+// triangle tr.
+func (t *Triangulation[P]) Circumcenter(tr int) P {
+	p := t.Triangle(tr)
+	x, y := circumcenter(p[0][0], p[0][1], p[1][0], p[1][1], p[2][0], p[2][1])
+	return P{x, y}
+}
+
+// Circumradius returns the radius of the circle through the points of the
+// triangle tr.
+func (t *Triangulation[P]) Circumradius(tr int) float64 {
+	p := t.Triangle(tr)
+	return math.Sqrt(circumradius(p[0][0], p[0][1], p[1][0], p[1][1], p[2][0], p[2][1]))
+}
+
+// EdgesAroundPoint returns the halfedges pointing to the point that the
+// halfedge start points to, turning around it from start. If start lies on the
+// convex hull, the walk ends at the other hull halfedge of the point, so start
+// with the incoming hull halfedge to visit all of them.
+func (t *Triangulation[P]) EdgesAroundPoint(start int) []int {
+	var result []int
+	incoming := start
+	for {
+		result = append(result, incoming)
+		incoming = t.Halfedges[NextHalfedge(incoming)]
+		if incoming == -1 || incoming == start {
+			break
+		}
+	}
+	return result
+}
+
+// Inedges returns for every point a halfedge pointing to it, or -1 if the
+// point is not triangulated. For points on the convex hull it is the hull
+// halfedge, which is the right start for EdgesAroundPoint.
+func (t *Triangulation[P]) Inedges() []int {
+	inedges := make([]int, len(t.Points))
+	for i := range inedges {
+		inedges[i] = -1
+	}
+	for e := range t.Triangles {
+		p := t.Triangles[NextHalfedge(e)]
+		if inedges[p] == -1 || t.Halfedges[e] == -1 {
+			inedges[p] = e
+		}
+	}
+	return inedges
+}
@@ -0,0 +1,44 @@
+package delaunay
+
+// Cell is the Voronoi cell of a point: the region of the plane closer to it
+// than to any other point.
+type Cell[P ~[2]float64] struct {
+	// the circumcenters of the triangles around the point, counterclockwise
+	// with the y axis pointing up
+	Vertices []P
+	// whether the cell is closed; cells of convex hull points extend to
+	// infinity beyond their first and last vertex
+	Bounded bool
+}
+
+// Circumcenters returns the circumcenters of all triangles, the vertices of
+// the Voronoi diagram.
+func (t *Triangulation[P]) Circumcenters() []P {
+	centers := make([]P, t.Len())
+	for i := range centers {
+		centers[i] = t.Circumcenter(i)
+	}
+	return centers
+}
+
+// Voronoi returns the Voronoi cells of the points, indexed like Points. Points
+// that are not triangulated, such as duplicates, get an empty cell.
+func (t *Triangulation[P]) Voronoi() []Cell[P] {
+	centers := t.Circumcenters()
+	cells := make([]Cell[P], len(t.Points))
+	for i, e := range t.Inedges() {
+		if e == -1 {
+			continue
+		}
+		edges := t.EdgesAroundPoint(e)
+		vertices := make([]P, len(edges))
+		for j, e := range edges {
+			vertices[j] = centers[TriangleOfEdge(e)]
+		}
+		cells[i] = Cell[P]{
+			Vertices: vertices,
+			Bounded:  t.Halfedges[e] != -1,
+		}
+	}
+	return cells
+}
@@ -0,0 +1,442 @@
+// Package flatbush is a static spatial index of boxes, a packed Hilbert
+// R-tree ported from https://github.com/mourner/flatbush. All boxes are added
+// up front, then the tree is built in one pass into a few flat arrays, which
+// makes it much lighter than a dynamic R-tree. Items cannot be added after
+// that, but they can be removed: removed items are only marked and skipped by
+// queries, and the node boxes are left as they are.
+package flatbush
+
+import (
+	"math"
+	"sync"
+)
+
+// Index is a packed Hilbert R-tree. Items are identified by the order in
+// which they were added, starting from 0.
+type Index struct {
+	numItems int
+	nodeSize int
+	// the end of each level, in slots; level 0 holds the items
+	levelBounds []int
+	// minX, minY, maxX, maxY of each slot
+	boxes []float64
+	// the item id of each item slot, and the first child slot of each node
+	indices []uint32
+	pos     int
+	min     [2]float64
+	max     [2]float64
+	// a bit per item id that is set once the item is removed
+	removed    []uint64
+	numRemoved int
+}
+
+// queues holds the queues of finished nearest searches for reuse
+var queues = sync.Pool{New: func() any { return new(queue) }}
+
+// New returns an index for numItems items with up to nodeSize children per
+// node; a nodeSize below 2 means the default of 16.
+func New(numItems, nodeSize int) *Index {
+	if numItems < 0 || uint64(numItems) > math.MaxUint32 {
+		panic("flatbush: invalid number of items")
+	}
+	if nodeSize < 2 {
+		nodeSize = 16
+	}
+
+	// calculate the total number of nodes in the R-tree to allocate space for
+	n := numItems
+	numNodes := n
+	levelBounds := []int{n}
+	for {
+		n = (n + nodeSize - 1) / nodeSize
+		numNodes += n
+		levelBounds = append(levelBounds, numNodes)
+		if n <= 1 {
+			break
+		}
+	}
+	if numItems == 0 {
+		// just the root
+		numNodes = 1
+		levelBounds = []int{0, 1}
+	}
+
+	return &Index{
+		numItems:    numItems,
+		nodeSize:    nodeSize,
+		levelBounds: levelBounds,
+		boxes:       make([]float64, 4*numNodes),
+		indices:     make([]uint32, numNodes),
+		min:         [2]float64{math.Inf(+1), math.Inf(+1)},
+		max:         [2]float64{math.Inf(-1), math.Inf(-1)},
+		removed:     make([]uint64, (numItems+63)/64),
+	}
+}
+
+// Add adds the box of the next item and returns its id. It panics if more
+// than the announced number of items are added.
+func (f *Index) Add(min, max [2]float64) int {
+	if f.pos >= f.numItems {
+		panic("flatbush: too many items added")
+	}
+	id := f.pos
+	f.indices[id] = uint32(id)
+	f.setBox(id, min, max)
+	f.pos++
+	for i := 0; i < 2; i++ {
+		f.min[i] = math.Min(f.min[i], min[i])
+		f.max[i] = math.Max(f.max[i], max[i])
+	}
+	return id
+}
+
+func (f *Index) setBox(slot int, min, max [2]float64) {
+	b := f.boxes[4*slot : 4*slot+4]
+	b[0], b[1], b[2], b[3] = min[0], min[1], max[0], max[1]
+}
+
+// Finish sorts the items along a Hilbert curve and builds the tree. It must
+// be called once, after all items are added and before any query.
+func (f *Index) Finish() {
+	if f.pos != f.numItems {
+		panic("flatbush: added fewer items than announced")
+	}
+	if f.numItems <= f.nodeSize {
+		// only one node, skip sorting and just fill the root box
+		f.indices[f.pos] = 0
+		f.setBox(f.pos, f.min, f.max)
+		f.pos++
+		return
+	}
+
+	width := f.max[0] - f.min[0]
+	if width == 0 {
+		width = 1
+	}
+	height := f.max[1] - f.min[1]
+	if height == 0 {
+		height = 1
+	}
+
+	// map item centers into Hilbert coordinate space and calculate Hilbert values
+	const hilbertMax = 1<<16 - 1
+	values := make([]uint32, f.numItems)
+	for i := range values {
+		b := f.boxes[4*i : 4*i+4]
+		x := uint32(math.Floor(hilbertMax * ((b[0]+b[2])/2 - f.min[0]) / width))
+		y := uint32(math.Floor(hilbertMax * ((b[1]+b[3])/2 - f.min[1]) / height))
+		values[i] = hilbert(x, y)
+	}
+
+	// sort items by their Hilbert value (for packing later)
+	f.sort(values, 0, f.numItems-1)
+
+	// generate nodes at each tree level, bottom-up
+	pos := 0
+	for i := 0; i < len(f.levelBounds)-1; i++ {
+		end := f.levelBounds[i]
+		for pos < end {
+			nodeIndex := pos
+
+			// calculate bbox for the new node
+			b := f.boxes[4*pos : 4*pos+4]
+			min := [2]float64{b[0], b[1]}
+			max := [2]float64{b[2], b[3]}
+			pos++
+			for j := 1; j < f.nodeSize && pos < end; j++ {
+				b := f.boxes[4*pos : 4*pos+4]
+				min[0] = math.Min(min[0], b[0])
+				min[1] = math.Min(min[1], b[1])
+				max[0] = math.Max(max[0], b[2])
+				max[1] = math.Max(max[1], b[3])
+				pos++
+			}
+
+			// add a new node to the tree data
+			f.indices[f.pos] = uint32(nodeIndex)
+			f.setBox(f.pos, min, max)
+			f.pos++
+		}
+	}
+}
+
+// sort sorts the items by their Hilbert values, only as far as needed to
+// fill the nodes
+func (f *Index) sort(values []uint32, left, right int) {
+	if left/f.nodeSize >= right/f.nodeSize {
+		return
+	}
+
+	pivot := values[(left+right)>>1]
+	i := left - 1
+	j := right + 1
+
+	for {
+		for {
+			i++
+			if values[i] >= pivot {
+				break
+			}
+		}
+		for {
+			j--
+			if values[j] <= pivot {
+				break
+			}
+		}
+		if i >= j {
+			break
+		}
+		f.swap(values, i, j)
+	}
+
+	f.sort(values, left, j)
+	f.sort(values, j+1, right)
+}
+
+func (f *Index) swap(values []uint32, i, j int) {
+	values[i], values[j] = values[j], values[i]
+	for k := 0; k < 4; k++ {
+		f.boxes[4*i+k], f.boxes[4*j+k] = f.boxes[4*j+k], f.boxes[4*i+k]
+	}
+	f.indices[i], f.indices[j] = f.indices[j], f.indices[i]
+}
+
+// Len returns the number of items that have not been removed.
+func (f *Index) Len() int {
+	return f.numItems - f.numRemoved
+}
+
+// Bounds returns the box holding all items added, including removed ones.
+func (f *Index) Bounds() (min, max [2]float64) {
+	return f.min, f.max
+}
+
+// Remove marks the item as removed, so that queries skip it, and reports
+// whether it was in the index.
+func (f *Index) Remove(id int) bool {
+	if id < 0 || id >= f.numItems || f.isRemoved(id) {
+		return false
+	}
+	f.removed[id>>6] |= 1 << (id & 63)
+	f.numRemoved++
+	return true
+}
+
+func (f *Index) isRemoved(id int) bool {
+	return f.removed[id>>6]&(1<<(id&63)) != 0
+}
+
+// upperBound returns the end of the level that holds the slot
+func (f *Index) upperBound(slot int) int {
+	for _, end := range f.levelBounds {
+		if end > slot {
+			return end
+		}
+	}
+	return f.levelBounds[len(f.levelBounds)-1]
+}
+
+// root returns the slot of the root node
+func (f *Index) root() int {
+	return len(f.indices) - 1
+}
+
+func (f *Index) checkFinished() {
+	if f.pos != len(f.indices) {
+		panic("flatbush: the index is not finished")
+	}
+}
+
+// Search calls iter for every item whose box intersects the box (min, max)
+// until iter returns false, in which case Search returns false.
+func (f *Index) Search(min, max [2]float64, iter func(id int) bool) bool {
+	f.checkFinished()
+	if f.numItems == 0 {
+		return true
+	}
+	var stack []int
+	nodeIndex := f.root()
+	for {
+		// find the end index of the node
+		end := f.upperBound(nodeIndex)
+		if e := nodeIndex + f.nodeSize; e < end {
+			end = e
+		}
+
+		// search through child nodes
+		for pos := nodeIndex; pos < end; pos++ {
+			b := f.boxes[4*pos : 4*pos+4]
+			if max[0] < b[0] || max[1] < b[1] || min[0] > b[2] || min[1] > b[3] {
+				continue
+			}
+			index := int(f.indices[pos])
+			if nodeIndex >= f.numItems {
+				stack = append(stack, index) // node; add it to the search stack
+			} else if !f.isRemoved(index) {
+				if !iter(index) {
+					return false
+				}
+			}
+		}
+
+		if len(stack) == 0 {
+			return true
+		}
+		nodeIndex = stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+	}
+}
+
+// NearestIter visits items in order of increasing distance with a best-first
+// traversal of the tree. boxDist must return a lower bound of itemDist for
+// every item inside the box. Nodes and items farther than maxDist are
+// skipped. Iteration stops when iter returns false, in which case NearestIter
+// returns false. Like the other queries, it may run concurrently with
+// queries but not with Remove.
+func (f *Index) NearestIter(boxDist func(min, max [2]float64) float64, itemDist func(id int) float64, maxDist float64, iter func(id int, dist float64) bool) bool {
+	return f.nearest(boxDist, func(id, _ int) float64 { return itemDist(id) }, maxDist, iter)
+}
+
+// nearest is NearestIter with itemDist also given the slot of the item
+func (f *Index) nearest(boxDist func(min, max [2]float64) float64, itemDist func(id, slot int) float64, maxDist float64, iter func(id int, dist float64) bool) bool {
+	f.checkFinished()
+	if f.numItems == 0 {
+		return true
+	}
+	q := queues.Get().(*queue)
+	defer func() {
+		q.clear()
+		queues.Put(q)
+	}()
+
+	nodeIndex := f.root()
+	for {
+		// find the end index of the node
+		end := f.upperBound(nodeIndex)
+		if e := nodeIndex + f.nodeSize; e < end {
+			end = e
+		}
+
+		// add child nodes and items to the queue
+		for pos := nodeIndex; pos < end; pos++ {
+			index := int(f.indices[pos])
+			if nodeIndex >= f.numItems {
+				b := f.boxes[4*pos : 4*pos+4]
+				dist := boxDist([2]float64{b[0], b[1]}, [2]float64{b[2], b[3]})
+				if dist > maxDist {
+					continue
+				}
+				q.push(index<<1, dist) // node (use even id)
+			} else if !f.isRemoved(index) {
+				dist := itemDist(index, pos)
+				if dist > maxDist {
+					continue
+				}
+				q.push(index<<1+1, dist) // leaf item (use odd id)
+			}
+		}
+
+		// pop items while they are closer than any node left in the queue
+		for q.len() > 0 && q.peek()&1 == 1 {
+			dist := q.peekValue()
+			if !iter(q.pop()>>1, dist) {
+				return false
+			}
+		}
+
+		if q.len() == 0 {
+			return true
+		}
+		nodeIndex = q.pop() >> 1
+	}
+}
+
+// Nearest returns the ids of up to k items closest to the point, ordered by
+// Euclidean distance to their boxes. Items farther than maxDist or rejected by
+// filter are left out. A k or maxDist of zero or less means no limit, and a
+// nil filter accepts every item.
+func (f *Index) Nearest(point [2]float64, k int, maxDist float64, filter func(id int) bool) []int {
+	limit := math.Inf(+1)
+	if maxDist > 0 {
+		limit = maxDist * maxDist
+	}
+	var result []int
+	f.nearest(func(min, max [2]float64) float64 {
+		return sqBoxDist(point, min, max)
+	}, func(_, slot int) float64 {
+		b := f.boxes[4*slot : 4*slot+4]
+		return sqBoxDist(point, [2]float64{b[0], b[1]}, [2]float64{b[2], b[3]})
+	}, limit, func(id int, dist float64) bool {
+		if filter == nil || filter(id) {
+			result = append(result, id)
+		}
+		return k <= 0 || len(result) < k
+	})
+	return result
+}
+
+// square distance from a point to a box
+func sqBoxDist(p, min, max [2]float64) float64 {
+	dx := axisDist(p[0], min[0], max[0])
+	dy := axisDist(p[1], min[1], max[1])
+	return dx*dx + dy*dy
+}
+
+func axisDist(k, min, max float64) float64 {
+	if k < min {
+		return min - k
+	}
+	if k <= max {
+		return 0
+	}
+	return k - max
+}
+
+// hilbert returns the position of (x, y) along a Hilbert curve of order 16,
+// using the method from https://github.com/rawrunprotected/hilbert_curves
+func hilbert(x, y uint32) uint32 {
+	a := x ^ y
+	b := 0xFFFF ^ a
+	c := 0xFFFF ^ (x | y)
+	d := x & (y ^ 0xFFFF)
+
+	A := a | (b >> 1)
+	B := (a >> 1) ^ a
+	C := ((c >> 1) ^ (b & (d >> 1))) ^ c
+	D := ((a & (c >> 1)) ^ (d >> 1)) ^ d
+
+	a, b, c, d = A, B, C, D
+	A = (a & (a >> 2)) ^ (b & (b >> 2))
+	B = (a & (b >> 2)) ^ (b & ((a ^ b) >> 2))
+	C ^= (a & (c >> 2)) ^ (b & (d >> 2))
+	D ^= (b & (c >> 2)) ^ ((a ^ b) & (d >> 2))
+
+	a, b, c, d = A, B, C, D
+	A = (a & (a >> 4)) ^ (b & (b >> 4))
+	B = (a & (b >> 4)) ^ (b & ((a ^ b) >> 4))
+	C ^= (a & (c >> 4)) ^ (b & (d >> 4))
+	D ^= (b & (c >> 4)) ^ ((a ^ b) & (d >> 4))
+
+	a, b, c, d = A, B, C, D
+	C ^= (a & (c >> 8)) ^ (b & (d >> 8))
+	D ^= (b & (c >> 8)) ^ ((a ^ b) & (d >> 8))
+
+	a = C ^ (C >> 1)
+	b = D ^ (D >> 1)
+
+	i0 := x ^ y
+	i1 := b | (0xFFFF ^ (i0 | a))
+
+	i0 = (i0 | (i0 << 8)) & 0x00FF00FF
+	i0 = (i0 | (i0 << 4)) & 0x0F0F0F0F
+	i0 = (i0 | (i0 << 2)) & 0x33333333
+	i0 = (i0 | (i0 << 1)) & 0x55555555
+
+	i1 = (i1 | (i1 << 8)) & 0x00FF00FF
+	i1 = (i1 | (i1 << 4)) & 0x0F0F0F0F
+	i1 = (i1 | (i1 << 2)) & 0x33333333
+	i1 = (i1 | (i1 << 1)) & 0x55555555
+
+	return (i1 << 1) | i0
+}
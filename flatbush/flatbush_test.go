@@ -0,0 +1,225 @@
+package flatbush_test
+
+import (
+	"math"
+	"math/rand"
+	"sort"
+	"sync"
+	"testing"
+
+	"github.com/wsw0108/concaveman-go/flatbush"
+	"github.com/wsw0108/concaveman-go/internal/testutil"
+)
+
+func build(boxes []testutil.Box, nodeSize int) *flatbush.Index {
+	index := flatbush.New(len(boxes), nodeSize)
+	for _, b := range boxes {
+		index.Add(b.Min, b.Max)
+	}
+	index.Finish()
+	return index
+}
+
+func searchIDs(index *flatbush.Index, min, max [2]float64) []int {
+	var ids []int
+	index.Search(min, max, func(id int) bool {
+		ids = append(ids, id)
+		return true
+	})
+	sort.Ints(ids)
+	return ids
+}
+
+func sqBoxDist(p [2]float64, b testutil.Box) float64 {
+	dx := math.Max(0, math.Max(b.Min[0]-p[0], p[0]-b.Max[0]))
+	dy := math.Max(0, math.Max(b.Min[1]-p[1], p[1]-b.Max[1]))
+	return dx*dx + dy*dy
+}
+
+func TestSearch(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	for _, n := range []int{1, 5, 16, 17, 1000} {
+		boxes := testutil.Boxes(r, n)
+		index := build(boxes, 16)
+		for i := 0; i < 50; i++ {
+			x, y := r.Float64()*100, r.Float64()*100
+			min, max := [2]float64{x, y}, [2]float64{x + 20, y + 20}
+			if got, want := searchIDs(index, min, max), testutil.Overlapping(boxes, nil, min, max); !testutil.EqualIDs(got, want) {
+				t.Errorf("%d boxes: search %v %v found %d boxes, want %d", n, min, max, len(got), len(want))
+			}
+		}
+		if min, max := index.Bounds(); min[0] < 0 || max[0] > 105 || min[0] > max[0] {
+			t.Errorf("%d boxes: bounds %v %v", n, min, max)
+		}
+	}
+}
+
+func TestRemove(t *testing.T) {
+	r := rand.New(rand.NewSource(2))
+	boxes := testutil.Boxes(r, 500)
+	index := build(boxes, 8)
+	removed := make(map[int]bool)
+	for _, id := range r.Perm(len(boxes))[:300] {
+		if !index.Remove(id) {
+			t.Fatalf("Remove(%d) = false", id)
+		}
+		removed[id] = true
+	}
+	if index.Remove(-1) || index.Remove(500) {
+		t.Error("removed an unknown id")
+	}
+	for id := range removed {
+		if index.Remove(id) {
+			t.Errorf("removed %d twice", id)
+		}
+		break
+	}
+	if index.Len() != 200 {
+		t.Errorf("Len() = %d, want 200", index.Len())
+	}
+	all := [2][2]float64{{0, 0}, {200, 200}}
+	if got, want := searchIDs(index, all[0], all[1]), testutil.Overlapping(boxes, removed, all[0], all[1]); !testutil.EqualIDs(got, want) {
+		t.Errorf("found %d boxes, want %d", len(got), len(want))
+	}
+	for _, id := range index.Nearest([2]float64{50, 50}, 0, 0, nil) {
+		if removed[id] {
+			t.Errorf("nearest found removed box %d", id)
+		}
+	}
+}
+
+func TestNearest(t *testing.T) {
+	r := rand.New(rand.NewSource(3))
+	boxes := testutil.Boxes(r, 1000)
+	index := build(boxes, 16)
+
+	tests := []struct {
+		name    string
+		q       [2]float64
+		k       int
+		maxDist float64
+	}{
+		{"k", [2]float64{50, 50}, 10, 0},
+		{"outside", [2]float64{-20, 130}, 5, 0},
+		{"max distance", [2]float64{25, 75}, 0, 3},
+		{"all", [2]float64{0, 0}, 0, 0},
+	}
+	for _, tt := range tests {
+		got := index.Nearest(tt.q, tt.k, tt.maxDist, nil)
+		var want []float64
+		for _, b := range boxes {
+			if d := sqBoxDist(tt.q, b); tt.maxDist <= 0 || d <= tt.maxDist*tt.maxDist {
+				want = append(want, d)
+			}
+		}
+		sort.Float64s(want)
+		if tt.k > 0 && len(want) > tt.k {
+			want = want[:tt.k]
+		}
+		if len(got) != len(want) {
+			t.Errorf("%s: got %d boxes, want %d", tt.name, len(got), len(want))
+			continue
+		}
+		for i, id := range got {
+			if d := sqBoxDist(tt.q, boxes[id]); d != want[i] {
+				t.Errorf("%s: box %d at distance %v, want %v", tt.name, i, d, want[i])
+			}
+		}
+	}
+
+	even := func(id int) bool { return id%2 == 0 }
+	for _, id := range index.Nearest([2]float64{50, 50}, 5, 0, even) {
+		if !even(id) {
+			t.Errorf("box %d does not pass the filter", id)
+		}
+	}
+}
+
+func TestNearestIter(t *testing.T) {
+	r := rand.New(rand.NewSource(4))
+	boxes := testutil.Boxes(r, 1000)
+	index := build(boxes, 4)
+
+	// distances to the vertical line x = 40
+	boxDist := func(min, max [2]float64) float64 {
+		return math.Max(0, math.Max(min[0]-40, 40-max[0]))
+	}
+	itemDist := func(id int) float64 {
+		return boxDist(boxes[id].Min, boxes[id].Max)
+	}
+	var n int
+	last := -1.0
+	done := index.NearestIter(boxDist, itemDist, 2, func(id int, d float64) bool {
+		if d < last || d != itemDist(id) {
+			t.Errorf("box %d at distance %v after %v", id, d, last)
+		}
+		last = d
+		n++
+		return true
+	})
+	var want int
+	for id := range boxes {
+		if itemDist(id) <= 2 {
+			want++
+		}
+	}
+	if !done || n != want {
+		t.Errorf("visited %d boxes, want %d", n, want)
+	}
+}
+
+func TestNearestConcurrent(t *testing.T) {
+	r := rand.New(rand.NewSource(5))
+	boxes := testutil.Boxes(r, 1000)
+	index := build(boxes, 16)
+	want := make([][]int, 8)
+	for i := range want {
+		want[i] = index.Nearest([2]float64{float64(10 * i), 50}, 20, 0, nil)
+	}
+
+	var wg sync.WaitGroup
+	for i := range want {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			for j := 0; j < 50; j++ {
+				if got := index.Nearest([2]float64{float64(10 * i), 50}, 20, 0, nil); !testutil.EqualIDs(got, want[i]) {
+					t.Errorf("query %d: got %v, want %v", i, got, want[i])
+					return
+				}
+			}
+		}(i)
+	}
+	wg.Wait()
+
+	// a search may also start from within another
+	var got []int
+	index.Nearest([2]float64{0, 50}, 1, 0, func(int) bool {
+		got = index.Nearest([2]float64{70, 50}, 20, 0, nil)
+		return true
+	})
+	if !testutil.EqualIDs(got, want[7]) {
+		t.Errorf("nested query: got %v, want %v", got, want[7])
+	}
+}
+
+func TestEmpty(t *testing.T) {
+	index := flatbush.New(0, 16)
+	index.Finish()
+	inf := math.Inf(1)
+	if ids := searchIDs(index, [2]float64{-inf, -inf}, [2]float64{inf, inf}); len(ids) != 0 {
+		t.Errorf("found %v", ids)
+	}
+	if ids := index.Nearest([2]float64{0, 0}, 1, 0, nil); len(ids) != 0 {
+		t.Errorf("nearest found %v", ids)
+	}
+}
+
+func BenchmarkFinish(b *testing.B) {
+	boxes := testutil.Boxes(rand.New(rand.NewSource(5)), 1000000)
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		build(boxes, 16)
+	}
+}
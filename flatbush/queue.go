@@ -0,0 +1,78 @@
+package flatbush
+
+// queue is a binary min-heap of ids by value, ported from
+// https://github.com/mourner/flatqueue; it keeps its arrays between uses
+type queue struct {
+	ids    []int
+	values []float64
+}
+
+func (q *queue) len() int {
+	return len(q.ids)
+}
+
+func (q *queue) clear() {
+	q.ids = q.ids[:0]
+	q.values = q.values[:0]
+}
+
+func (q *queue) push(id int, value float64) {
+	pos := len(q.ids)
+	q.ids = append(q.ids, id)
+	q.values = append(q.values, value)
+
+	for pos > 0 {
+		parent := (pos - 1) >> 1
+		parentValue := q.values[parent]
+		if value >= parentValue {
+			break
+		}
+		q.ids[pos] = q.ids[parent]
+		q.values[pos] = parentValue
+		pos = parent
+	}
+
+	q.ids[pos] = id
+	q.values[pos] = value
+}
+
+func (q *queue) pop() int {
+	top := q.ids[0]
+	last := len(q.ids) - 1
+	id := q.ids[last]
+	value := q.values[last]
+	q.ids = q.ids[:last]
+	q.values = q.values[:last]
+
+	if last > 0 {
+		pos := 0
+		half := last >> 1
+		for pos < half {
+			left := pos<<1 + 1
+			right := left + 1
+			bestIndex := left
+			bestValue := q.values[left]
+			if right < last && q.values[right] < bestValue {
+				bestIndex = right
+				bestValue = q.values[right]
+			}
+			if bestValue >= value {
+				break
+			}
+			q.ids[pos] = q.ids[bestIndex]
+			q.values[pos] = bestValue
+			pos = bestIndex
+		}
+		q.ids[pos] = id
+		q.values[pos] = value
+	}
+	return top
+}
+
+func (q *queue) peek() int {
+	return q.ids[0]
+}
+
+func (q *queue) peekValue() float64 {
+	return q.values[0]
+}
@@ -0,0 +1,31 @@
+package concaveman
+
+import "context"
+
+// ConcavemanFunc computes a concave hull of arbitrary items, using xy to get
+// the coordinates of each item, and returns the hull as a closed ring of the
+// items themselves. Degenerate input yields the items of the fallback results
+// documented on ConcavemanE.
+func ConcavemanFunc[T any](items []T, xy func(T) (float64, float64), opts ...Options) []T {
+	result, _ := ConcavemanFuncE(items, xy, opts...)
+	return result
+}
+
+// ConcavemanFuncE is like ConcavemanFunc but also returns the error described
+// on ConcavemanE for degenerate input, along with the items of the fallback
+// result.
+func ConcavemanFuncE[T any](items []T, xy func(T) (float64, float64), opts ...Options) ([]T, error) {
+	points := make([]Point, len(items))
+	for i, item := range items {
+		points[i][0], points[i][1] = xy(item)
+	}
+	indices, err := concaveman(context.Background(), points, getOptions(opts))
+	if indices == nil {
+		return nil, err
+	}
+	result := make([]T, len(indices))
+	for i, j := range indices {
+		result[i] = items[j]
+	}
+	return result, err
+}
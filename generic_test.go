@@ -0,0 +1,58 @@
+package concaveman_test
+
+import (
+	"errors"
+	"math"
+	"reflect"
+	"testing"
+
+	"github.com/wsw0108/concaveman-go"
+)
+
+type record struct {
+	id   int
+	x, y float64
+}
+
+func TestConcavemanFunc(t *testing.T) {
+	records := make([]record, len(g_points))
+	for i, p := range g_points {
+		records[i] = record{id: i, x: p[0], y: p[1]}
+	}
+	result := concaveman.ConcavemanFunc(records, func(r record) (float64, float64) {
+		return r.x, r.y
+	})
+	hull := make([]concaveman.Point, len(result))
+	for i, r := range result {
+		if g_points[r.id] != (concaveman.Point{r.x, r.y}) {
+			t.Fatalf("TestConcavemanFunc: record %d lost its identity", r.id)
+		}
+		hull[i] = concaveman.Point{r.x, r.y}
+	}
+	if !reflect.DeepEqual(hull, g_hull) {
+		t.Error("TestConcavemanFunc")
+	}
+	if result[0].id != result[len(result)-1].id {
+		t.Error("TestConcavemanFunc: open ring")
+	}
+}
+
+func TestConcavemanFuncE(t *testing.T) {
+	xy := func(r record) (float64, float64) {
+		return r.x, r.y
+	}
+	records := []record{{1, 0, 0}, {2, 1, 1}, {3, 2, 2}}
+	result, err := concaveman.ConcavemanFuncE(records, xy)
+	if !errors.Is(err, concaveman.ErrCollinear) {
+		t.Errorf("TestConcavemanFuncE: error = %v", err)
+	}
+	if len(result) != 3 || result[0].id != 1 || result[1].id != 3 || result[2].id != 1 {
+		t.Errorf("TestConcavemanFuncE: collinear = %v", result)
+	}
+
+	records = append(records, record{4, math.NaN(), 0})
+	result, err = concaveman.ConcavemanFuncE(records, xy)
+	if !errors.Is(err, concaveman.ErrNonFinite) || result != nil {
+		t.Errorf("TestConcavemanFuncE: got %v, %v", result, err)
+	}
+}
@@ -0,0 +1,250 @@
+package concaveman
+
+import (
+	"math"
+	"sort"
+)
+
+// earthRadius is the mean radius of the Earth in meters
+const earthRadius = 6371008.8
+
+const deg2rad = math.Pi / 180
+
+// sphere measures great-circle distances in meters between [lon, lat] points
+// given in degrees
+type sphere struct{}
+
+func (sphere) sqDist(p1, p2 Point) float64 {
+	d := haversine(p1, p2)
+	return d * d
+}
+
+func (sphere) segment(a, b Point) segment {
+	s := &arc{a: a, b: b, va: unitVector(a), vb: unitVector(b)}
+	s.n = cross3(s.va, s.vb)
+	l := norm3(s.n)
+	if l < 1e-15 {
+		// coincident or antipodal endpoints; measure to a only
+		s.degenerate = true
+		return s
+	}
+	s.n = scale3(s.n, 1/l)
+
+	// the bounding box of the arc, which bulges towards the poles
+	s.minX, s.maxX = math.Min(a[0], b[0]), math.Max(a[0], b[0])
+	s.minY, s.maxY = math.Min(a[1], b[1]), math.Max(a[1], b[1])
+	if s.maxX-s.minX >= 180 {
+		s.minX, s.maxX = math.Inf(-1), math.Inf(+1)
+	}
+	// the northernmost point of the great circle, unless it is the equator
+	v := [3]float64{-s.n[2] * s.n[0], -s.n[2] * s.n[1], 1 - s.n[2]*s.n[2]}
+	if l := norm3(v); l > 1e-15 {
+		v = scale3(v, 1/l)
+		lat := math.Asin(math.Min(1, v[2])) / deg2rad
+		if s.within(v) {
+			s.maxY = math.Max(s.maxY, lat)
+		}
+		if s.within(scale3(v, -1)) {
+			s.minY = math.Min(s.minY, -lat)
+		}
+	}
+	return s
+}
+
+func (sphere) bounds(p Point, distance float64) node {
+	delta := distance / earthRadius
+	dLat := delta / deg2rad
+	b := node{
+		minX: math.Inf(-1),
+		minY: p[1] - dLat,
+		maxX: math.Inf(+1),
+		maxY: p[1] + dLat,
+	}
+	// the longitude extent of a spherical cap that doesn't contain a pole
+	if delta < math.Pi/2 && b.minY > -90 && b.maxY < 90 {
+		dLon := math.Asin(math.Sin(delta)/math.Cos(p[1]*deg2rad)) / deg2rad
+		b.minX = p[0] - dLon
+		b.maxX = p[0] + dLon
+	}
+	return b
+}
+
+func (sphere) sqSpan(min, max [2]float64) float64 {
+	// along a meridian to the latitude closest to the equator, along that
+	// parallel, and along a meridian again
+	d := earthRadius * deg2rad * (2*(max[1]-min[1]) + (max[0] - min[0]))
+	d = math.Min(d, math.Pi*earthRadius)
+	return d * d
+}
+
+// arc is a great-circle segment
+type arc struct {
+	a, b       Point
+	va, vb     [3]float64
+	n          [3]float64 // unit normal of the great circle
+	degenerate bool
+	minX       float64
+	minY       float64
+	maxX       float64
+	maxY       float64
+}
+
+// within reports whether the point v of the great circle lies on the arc
+func (s *arc) within(v [3]float64) bool {
+	return dot3(cross3(s.va, v), s.n) >= 0 && dot3(cross3(v, s.vb), s.n) >= 0
+}
+
+func (s *arc) sqDist(p Point) float64 {
+	if s.degenerate {
+		return sphere{}.sqDist(p, s.a)
+	}
+	vp := unitVector(p)
+	sin := dot3(vp, s.n)
+	// project p onto the great circle; if the projection falls on the arc,
+	// the cross-track distance is the answer
+	c := [3]float64{vp[0] - sin*s.n[0], vp[1] - sin*s.n[1], vp[2] - sin*s.n[2]}
+	if s.within(c) {
+		d := earthRadius * math.Asin(math.Min(1, math.Abs(sin)))
+		return d * d
+	}
+	d := math.Min(haversine(p, s.a), haversine(p, s.b))
+	return d * d
+}
+
+// sqBoxDist bounds the distance from below through the haversine formula:
+// with the latitudes of both points at most φ in magnitude, a great-circle
+// distance d satisfies d ≥ 2/π·√(Δlat² + cos²φ·Δlon²) in radians
+func (s *arc) sqBoxDist(min, max [2]float64) float64 {
+	minX, minY, maxX, maxY := s.minX, s.minY, s.maxX, s.maxY
+	if s.degenerate {
+		minX, maxX = s.a[0], s.a[0]
+		minY, maxY = s.a[1], s.a[1]
+	}
+	dx := math.Max(0, math.Max(min[0]-maxX, minX-max[0]))
+	dy := math.Max(0, math.Max(min[1]-maxY, minY-max[1]))
+	if math.Max(maxX, max[0])-math.Min(minX, min[0]) > 180 {
+		// the longitude difference may be shorter the other way around
+		dx = 0
+	}
+	lat := math.Max(math.Max(math.Abs(minY), math.Abs(maxY)),
+		math.Max(math.Abs(min[1]), math.Abs(max[1])))
+	c := math.Cos(math.Min(90, lat) * deg2rad)
+	d := 2 / math.Pi * earthRadius * deg2rad * math.Hypot(dy, c*dx)
+	return d * d
+}
+
+// haversine returns the great-circle distance in meters
+func haversine(p1, p2 Point) float64 {
+	lat1 := p1[1] * deg2rad
+	lat2 := p2[1] * deg2rad
+	sinLat := math.Sin((lat2 - lat1) / 2)
+	sinLon := math.Sin((p2[0] - p1[0]) * deg2rad / 2)
+	h := sinLat*sinLat + math.Cos(lat1)*math.Cos(lat2)*sinLon*sinLon
+	return 2 * earthRadius * math.Asin(math.Sqrt(math.Min(1, h)))
+}
+
+func unitVector(p Point) [3]float64 {
+	lon := p[0] * deg2rad
+	lat := p[1] * deg2rad
+	return [3]float64{
+		math.Cos(lat) * math.Cos(lon),
+		math.Cos(lat) * math.Sin(lon),
+		math.Sin(lat),
+	}
+}
+
+func cross3(a, b [3]float64) [3]float64 {
+	return [3]float64{
+		a[1]*b[2] - a[2]*b[1],
+		a[2]*b[0] - a[0]*b[2],
+		a[0]*b[1] - a[1]*b[0],
+	}
+}
+
+func dot3(a, b [3]float64) float64 {
+	return a[0]*b[0] + a[1]*b[1] + a[2]*b[2]
+}
+
+func norm3(a [3]float64) float64 {
+	return math.Sqrt(dot3(a, a))
+}
+
+func scale3(a [3]float64, f float64) [3]float64 {
+	return [3]float64{a[0] * f, a[1] * f, a[2] * f}
+}
+
+// unwrap shifts longitudes by multiples of 360° so that the points span less
+// than a full turn, cutting the circle of longitudes at the widest gap between
+// the points. Point sets crossing the antimeridian thus stay contiguous. The
+// points are returned as is if a coordinate is not finite.
+func unwrap(points []Point) []Point {
+	cut, ok := unwrapCut(points)
+	if !ok {
+		return points
+	}
+	result := make([]Point, len(points))
+	for i, p := range points {
+		result[i] = Point{unwrapLon(p[0], cut), p[1]}
+	}
+	return result
+}
+
+// unwrapCut returns the longitude in [-180, 180) up to which unwrap shifts
+// longitudes by a full turn, or false if a coordinate is not finite
+func unwrapCut(points []Point) (float64, bool) {
+	lons := make([]float64, len(points))
+	for i, p := range points {
+		if !isFinite(p[0]) || !isFinite(p[1]) {
+			return 0, false
+		}
+		lons[i] = unwrapLon(p[0], math.Inf(-1))
+	}
+	if len(lons) == 0 {
+		return math.Inf(-1), true
+	}
+	sort.Float64s(lons)
+
+	// start with the gap across the antimeridian, which needs no shift
+	gap := lons[0] + 360 - lons[len(lons)-1]
+	cut := math.Inf(-1)
+	for i := 1; i < len(lons); i++ {
+		if d := lons[i] - lons[i-1]; d > gap {
+			gap = d
+			cut = lons[i-1]
+		}
+	}
+	return cut, true
+}
+
+// unwrapLon brings lon into [-180, 180), then shifts it by a full turn if it
+// is at most cut
+func unwrapLon(lon, cut float64) float64 {
+	lon = math.Mod(lon+180, 360)
+	if lon < 0 {
+		lon += 360
+	}
+	lon -= 180
+	if lon <= cut {
+		lon += 360
+	}
+	return lon
+}
+
+// localPlane maps unwrapped [lon, lat] points to meters with an
+// equirectangular projection centered on their latitude range
+func localPlane(points []Point) []Point {
+	minLat, maxLat := math.Inf(+1), math.Inf(-1)
+	for _, p := range points {
+		minLat = math.Min(minLat, p[1])
+		maxLat = math.Max(maxLat, p[1])
+	}
+	c := math.Cos((minLat + maxLat) / 2 * deg2rad)
+	result := make([]Point, len(points))
+	for i, p := range points {
+		result[i] = Point{
+			earthRadius * p[0] * deg2rad * c,
+			earthRadius * p[1] * deg2rad,
+		}
+	}
+	return result
+}
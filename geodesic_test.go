@@ -0,0 +1,160 @@
+package concaveman_test
+
+import (
+	"context"
+	"math"
+	"reflect"
+	"testing"
+
+	"github.com/wsw0108/concaveman-go"
+)
+
+func TestGeodesicAntimeridian(t *testing.T) {
+	// a grid from 178°E to 178°W
+	var points []concaveman.Point
+	for x := 178.0; x <= 182; x += 0.5 {
+		for y := -2.0; y <= 2; y += 0.5 {
+			lon := x
+			if lon >= 180 {
+				lon -= 360
+			}
+			points = append(points, concaveman.Point{lon, y})
+		}
+	}
+
+	hull, err := concaveman.ConcavemanE(points, concaveman.Options{Concavity: 2, Geodesic: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(hull) < 5 {
+		t.Fatalf("TestGeodesicAntimeridian: got %v", hull)
+	}
+	// with longitudes taken in [0, 360) the hull covers the 4°×4° grid
+	unwrapped := make([]concaveman.Point, len(hull))
+	for i, p := range hull {
+		unwrapped[i] = p
+		if p[0] < 0 {
+			unwrapped[i][0] += 360
+		}
+	}
+	if a := math.Abs(concaveman.RingArea(unwrapped)); math.Abs(a-16) > 0.5 {
+		t.Errorf("TestGeodesicAntimeridian: got area %v, want 16", a)
+	}
+	// the planar hull spans all longitudes in between
+	planar := concaveman.Concaveman(points)
+	if !concaveman.PointInPolygon(concaveman.Point{0, 0}, planar) {
+		t.Errorf("TestGeodesicAntimeridian: planar hull = %v", planar)
+	}
+}
+
+func TestGeodesicHull(t *testing.T) {
+	var points []concaveman.Point
+	for x := 178.0; x <= 182; x += 0.5 {
+		for y := -2.0; y <= 2; y += 0.5 {
+			lon := x
+			if lon >= 180 {
+				lon -= 360
+			}
+			points = append(points, concaveman.Point{lon, y})
+		}
+	}
+	opt := concaveman.Options{Concavity: 2, Geodesic: true}
+	h, err := concaveman.NewHullOptions(points, opt)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want, _ := concaveman.ConcavemanE(points, opt)
+	got, _ := h.RefineContext(context.Background(), opt)
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("TestGeodesicHull: got %v, want %v", got, want)
+	}
+	// the convex hull is the grid boundary, in input coordinates
+	for _, p := range h.ConvexHull() {
+		if p[0] > -178 && p[0] < 178 || math.Abs(p[1]) > 2 {
+			t.Errorf("TestGeodesicHull: convex hull point %v", p)
+		}
+	}
+
+	// points are added and removed in input coordinates too
+	if err := h.AddPoints([]concaveman.Point{{-177, 0}}); err != nil {
+		t.Fatal(err)
+	}
+	if !containsPoint(h.Points(), concaveman.Point{-177, 0}) {
+		t.Errorf("TestGeodesicHull: added point missing from %v", h.Points())
+	}
+	if err := h.RemovePoints([]concaveman.Point{{-177, 0}}); err != nil {
+		t.Fatal(err)
+	}
+	if containsPoint(h.Points(), concaveman.Point{-177, 0}) {
+		t.Errorf("TestGeodesicHull: removed point still in %v", h.Points())
+	}
+}
+
+func containsPoint(ring []concaveman.Point, p concaveman.Point) bool {
+	for _, q := range ring {
+		if q == p {
+			return true
+		}
+	}
+	return false
+}
+
+func TestGeodesicLengthThreshold(t *testing.T) {
+	testLengthThreshold(t, "TestGeodesicLengthThreshold", concaveman.Options{Concavity: 1, Geodesic: true})
+}
+
+// uShape returns a U-shaped set of points near 70°N, about 38km wide
+func uShape() []concaveman.Point {
+	var points []concaveman.Point
+	for x := 0; x <= 10; x++ {
+		for y := 0; y <= 10; y++ {
+			if x <= 2 || x >= 8 || y <= 2 {
+				points = append(points, concaveman.Point{float64(x) * 0.1, 70 + float64(y)*0.05})
+			}
+		}
+	}
+	return points
+}
+
+// testLengthThreshold checks that opt makes the length threshold apply in
+// meters
+func testLengthThreshold(t *testing.T, name string, opt concaveman.Options) {
+	points := uShape()
+	notch := func(hull []concaveman.Point) bool {
+		for _, p := range hull {
+			if p[0] > 0.25 && p[0] < 0.75 && p[1] > 70.05 {
+				return true
+			}
+		}
+		return false
+	}
+
+	opt.LengthThreshold = 50000
+	hull := concaveman.Concaveman(points, opt)
+	if notch(hull) {
+		t.Errorf("%s: dug below 50km: %v", name, hull)
+	}
+	opt.LengthThreshold = 1000
+	hull = concaveman.Concaveman(points, opt)
+	if !notch(hull) {
+		t.Errorf("%s: didn't dig into the U: %v", name, hull)
+	}
+}
+
+func TestGeodesicMulti(t *testing.T) {
+	// two clusters 50km apart across the antimeridian, and one far away
+	var points []concaveman.Point
+	for _, lon := range []float64{179.5, -179.8, 10} {
+		for i := 0; i < 4; i++ {
+			for j := 0; j < 4; j++ {
+				points = append(points, concaveman.Point{lon + float64(i)*0.01, float64(j) * 0.01})
+			}
+		}
+	}
+	if got := len(concaveman.ConcavemanMulti(points, 20000, concaveman.Options{Geodesic: true})); got != 3 {
+		t.Errorf("TestGeodesicMulti: got %d clusters at 20km, want 3", got)
+	}
+	if got := len(concaveman.ConcavemanMulti(points, 100000, concaveman.Options{Geodesic: true})); got != 2 {
+		t.Errorf("TestGeodesicMulti: got %d clusters at 100km, want 2", got)
+	}
+}
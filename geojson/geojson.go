@@ -0,0 +1,260 @@
+// Package geojson reads input points for concaveman from GeoJSON and writes
+// the resulting hulls as GeoJSON features.
+package geojson
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/wsw0108/concaveman-go"
+)
+
+// Geometry is a GeoJSON geometry object.
+type Geometry struct {
+	Type        string          `json:"type"`
+	Coordinates json.RawMessage `json:"coordinates,omitempty"`
+	Geometries  []Geometry      `json:"geometries,omitempty"`
+}
+
+// Feature is a GeoJSON feature object.
+type Feature struct {
+	Type       string                 `json:"type"`
+	Geometry   *Geometry              `json:"geometry"`
+	Properties map[string]interface{} `json:"properties"`
+}
+
+// FeatureCollection is a GeoJSON feature collection object.
+type FeatureCollection struct {
+	Type     string    `json:"type"`
+	Features []Feature `json:"features"`
+}
+
+// object holds the members of any GeoJSON object we read
+type object struct {
+	Type        string          `json:"type"`
+	Coordinates json.RawMessage `json:"coordinates"`
+	Geometries  []Geometry      `json:"geometries"`
+	Geometry    *Geometry       `json:"geometry"`
+	Features    []Feature       `json:"features"`
+}
+
+// ErrUnsupportedType is returned for GeoJSON objects of an unknown type.
+var ErrUnsupportedType = errors.New("geojson: unsupported type")
+
+// ReadPoints decodes a GeoJSON object from r and returns all of its positions.
+// See DecodePoints.
+func ReadPoints(r io.Reader) ([]concaveman.Point, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	return DecodePoints(data)
+}
+
+// DecodePoints decodes a GeoJSON FeatureCollection, Feature or geometry and
+// returns all of its positions, including the vertices of lines and polygons.
+// Altitudes and other extra coordinates are dropped.
+func DecodePoints(data []byte) ([]concaveman.Point, error) {
+	var obj object
+	if err := json.Unmarshal(data, &obj); err != nil {
+		return nil, err
+	}
+	var points []concaveman.Point
+	err := obj.appendPoints(&points)
+	return points, err
+}
+
+func (obj *object) appendPoints(points *[]concaveman.Point) error {
+	switch obj.Type {
+	case "FeatureCollection":
+		for i := range obj.Features {
+			if err := appendFeature(points, &obj.Features[i]); err != nil {
+				return err
+			}
+		}
+		return nil
+	case "Feature":
+		if obj.Geometry == nil {
+			return nil
+		}
+		return appendGeometry(points, obj.Geometry)
+	default:
+		return appendGeometry(points, &Geometry{
+			Type:        obj.Type,
+			Coordinates: obj.Coordinates,
+			Geometries:  obj.Geometries,
+		})
+	}
+}
+
+func appendFeature(points *[]concaveman.Point, f *Feature) error {
+	if f.Geometry == nil {
+		return nil
+	}
+	return appendGeometry(points, f.Geometry)
+}
+
+func appendGeometry(points *[]concaveman.Point, g *Geometry) error {
+	var depth int
+	switch g.Type {
+	case "Point":
+		depth = 0
+	case "MultiPoint", "LineString":
+		depth = 1
+	case "MultiLineString", "Polygon":
+		depth = 2
+	case "MultiPolygon":
+		depth = 3
+	case "GeometryCollection":
+		for i := range g.Geometries {
+			if err := appendGeometry(points, &g.Geometries[i]); err != nil {
+				return err
+			}
+		}
+		return nil
+	default:
+		return fmt.Errorf("%w %q", ErrUnsupportedType, g.Type)
+	}
+	if len(g.Coordinates) == 0 {
+		return fmt.Errorf("geojson: %s without coordinates", g.Type)
+	}
+	return appendPositions(points, g.Coordinates, depth)
+}
+
+// appendPositions decodes nested arrays of positions, depth levels deep
+func appendPositions(points *[]concaveman.Point, data json.RawMessage, depth int) error {
+	if depth == 0 {
+		var pos []float64
+		if err := json.Unmarshal(data, &pos); err != nil {
+			return err
+		}
+		if len(pos) < 2 {
+			return fmt.Errorf("geojson: position with %d coordinates", len(pos))
+		}
+		*points = append(*points, concaveman.Point{pos[0], pos[1]})
+		return nil
+	}
+	var items []json.RawMessage
+	if err := json.Unmarshal(data, &items); err != nil {
+		return err
+	}
+	for _, item := range items {
+		if err := appendPositions(points, item, depth-1); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// HullFeature returns a Polygon feature for a hull computed by Concaveman,
+// with the options used, the number of input points and the area of the hull,
+// as computed by Polygon.Area with the same options, as properties. A hull
+// with fewer than four points, such as a fallback result of ConcavemanE, is
+// not a valid polygon and yields a feature with a null geometry.
+func HullFeature(hull []concaveman.Point, pointCount int, opt concaveman.Options) (*Feature, error) {
+	return PolygonFeature(concaveman.Polygon{hull}, pointCount, opt)
+}
+
+// PolygonFeature is like HullFeature for a polygon with holes, as computed by
+// ConcavemanPolygon. Following RFC 7946, the outer ring is written
+// counterclockwise and holes clockwise.
+func PolygonFeature(poly concaveman.Polygon, pointCount int, opt concaveman.Options) (*Feature, error) {
+	f := &Feature{
+		Type:       "Feature",
+		Properties: properties(pointCount, opt, 0),
+	}
+	if !valid(poly) {
+		return f, nil
+	}
+	coords, err := json.Marshal(poly.Rewind(false))
+	if err != nil {
+		return nil, err
+	}
+	f.Geometry = &Geometry{
+		Type:        "Polygon",
+		Coordinates: coords,
+	}
+	f.Properties["area"] = poly.Area(opt)
+	return f, nil
+}
+
+// MultiPolygonFeature is like PolygonFeature for the polygons computed by
+// ConcavemanMulti. Invalid polygons are left out.
+func MultiPolygonFeature(polys []concaveman.Polygon, pointCount int, opt concaveman.Options) (*Feature, error) {
+	var oriented []concaveman.Polygon
+	var total float64
+	for _, poly := range polys {
+		if valid(poly) {
+			oriented = append(oriented, poly.Rewind(false))
+			total += poly.Area(opt)
+		}
+	}
+	f := &Feature{
+		Type:       "Feature",
+		Properties: properties(pointCount, opt, total),
+	}
+	if len(oriented) == 0 {
+		return f, nil
+	}
+	coords, err := json.Marshal(oriented)
+	if err != nil {
+		return nil, err
+	}
+	f.Geometry = &Geometry{
+		Type:        "MultiPolygon",
+		Coordinates: coords,
+	}
+	return f, nil
+}
+
+// WriteHull writes the feature returned by HullFeature to w.
+func WriteHull(w io.Writer, hull []concaveman.Point, pointCount int, opt concaveman.Options) error {
+	f, err := HullFeature(hull, pointCount, opt)
+	if err != nil {
+		return err
+	}
+	return json.NewEncoder(w).Encode(f)
+}
+
+// valid reports whether the polygon has an outer ring and all its rings are
+// linear rings of at least four positions
+func valid(poly concaveman.Polygon) bool {
+	if len(poly) == 0 {
+		return false
+	}
+	for _, ring := range poly {
+		if len(ring) < 4 {
+			return false
+		}
+	}
+	return true
+}
+
+func properties(pointCount int, opt concaveman.Options, area float64) map[string]interface{} {
+	props := map[string]interface{}{
+		"concavity":       opt.Concavity,
+		"lengthThreshold": opt.LengthThreshold,
+		"pointCount":      pointCount,
+		"area":            area,
+	}
+	if opt.MinHoleArea > 0 {
+		props["minHoleArea"] = opt.MinHoleArea
+	}
+	if opt.Geodesic {
+		props["geodesic"] = true
+	}
+	if opt.Projection != nil {
+		props["projection"] = projectionName(opt.Projection)
+	}
+	return props
+}
+
+// projectionName describes a projection by its String method or else its type
+func projectionName(p concaveman.Projection) string {
+	if s, ok := p.(fmt.Stringer); ok {
+		return s.String()
+	}
+	return fmt.Sprintf("%T", p)
+}
@@ -0,0 +1,176 @@
+package geojson_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"math"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/wsw0108/concaveman-go"
+	"github.com/wsw0108/concaveman-go/geojson"
+	"github.com/wsw0108/concaveman-go/proj"
+)
+
+func TestDecodePoints(t *testing.T) {
+	tests := []struct {
+		name string
+		data string
+		want []concaveman.Point
+	}{
+		{
+			name: "Point",
+			data: `{"type":"Point","coordinates":[1,2,3]}`,
+			want: []concaveman.Point{{1, 2}},
+		},
+		{
+			name: "MultiPoint",
+			data: `{"type":"MultiPoint","coordinates":[[1,2],[3,4]]}`,
+			want: []concaveman.Point{{1, 2}, {3, 4}},
+		},
+		{
+			name: "Polygon",
+			data: `{"type":"Polygon","coordinates":[[[0,0],[1,0],[0,1],[0,0]]]}`,
+			want: []concaveman.Point{{0, 0}, {1, 0}, {0, 1}, {0, 0}},
+		},
+		{
+			name: "GeometryCollection",
+			data: `{"type":"GeometryCollection","geometries":[
+				{"type":"Point","coordinates":[1,2]},
+				{"type":"LineString","coordinates":[[3,4],[5,6]]}
+			]}`,
+			want: []concaveman.Point{{1, 2}, {3, 4}, {5, 6}},
+		},
+		{
+			name: "FeatureCollection",
+			data: `{"type":"FeatureCollection","features":[
+				{"type":"Feature","geometry":{"type":"Point","coordinates":[1,2]},"properties":{"id":1}},
+				{"type":"Feature","geometry":null,"properties":null},
+				{"type":"Feature","geometry":{"type":"MultiPoint","coordinates":[[3,4]]},"properties":{}}
+			]}`,
+			want: []concaveman.Point{{1, 2}, {3, 4}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := geojson.DecodePoints([]byte(tt.data))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("DecodePoints() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+
+	_, err := geojson.DecodePoints([]byte(`{"type":"Circle","coordinates":[0,0]}`))
+	if !errors.Is(err, geojson.ErrUnsupportedType) {
+		t.Errorf("DecodePoints() error = %v", err)
+	}
+	_, err = geojson.DecodePoints([]byte(`{"type":"Point","coordinates":[0]}`))
+	if err == nil {
+		t.Error("DecodePoints() accepted a short position")
+	}
+}
+
+func TestWriteHull(t *testing.T) {
+	points, err := geojson.ReadPoints(strings.NewReader(`{"type":"MultiPoint","coordinates":[[0,0],[2,0],[1,2],[1,1]]}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	opt := concaveman.Options{Concavity: 2}
+	hull := concaveman.Concaveman(points, opt)
+
+	var buf bytes.Buffer
+	if err := geojson.WriteHull(&buf, hull, len(points), opt); err != nil {
+		t.Fatal(err)
+	}
+
+	var f struct {
+		Type     string
+		Geometry struct {
+			Type        string
+			Coordinates [][][2]float64
+		}
+		Properties map[string]float64
+	}
+	if err := json.Unmarshal(buf.Bytes(), &f); err != nil {
+		t.Fatal(err)
+	}
+	if f.Type != "Feature" || f.Geometry.Type != "Polygon" {
+		t.Errorf("WriteHull() wrote a %s of %s", f.Type, f.Geometry.Type)
+	}
+	// counterclockwise
+	want := [][2]float64{{2, 0}, {1, 1}, {1, 2}, {0, 0}, {2, 0}}
+	if !reflect.DeepEqual(f.Geometry.Coordinates, [][][2]float64{want}) {
+		t.Errorf("WriteHull() coordinates = %v", f.Geometry.Coordinates)
+	}
+	wantProps := map[string]float64{
+		"concavity":       2,
+		"lengthThreshold": 0,
+		"pointCount":      4,
+		"area":            1.5,
+	}
+	if !reflect.DeepEqual(f.Properties, wantProps) {
+		t.Errorf("WriteHull() properties = %v", f.Properties)
+	}
+
+	back, err := geojson.DecodePoints(buf.Bytes())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(back) != len(hull) {
+		t.Errorf("DecodePoints() read %d points back, want %d", len(back), len(hull))
+	}
+}
+
+func TestPolygonFeatureOptions(t *testing.T) {
+	// one degree square on the equator
+	square := concaveman.Polygon{{{0, 0}, {0, 1}, {1, 1}, {1, 0}, {0, 0}}}
+	want := proj.EarthRadius * proj.EarthRadius * math.Pi / 180 * math.Sin(math.Pi/180)
+
+	f, err := geojson.PolygonFeature(square, 4, concaveman.Options{Geodesic: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if a := f.Properties["area"].(float64); math.Abs(a-want) > 1e-6*want {
+		t.Errorf("geodesic area = %v, want %v", a, want)
+	}
+	if f.Properties["geodesic"] != true {
+		t.Errorf("properties = %v", f.Properties)
+	}
+
+	f, err = geojson.PolygonFeature(square, 4, concaveman.Options{Projection: proj.LambertAzimuthal{Lon: 0.5, Lat: 0.5}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if a := f.Properties["area"].(float64); math.Abs(a-want) > 1e-3*want {
+		t.Errorf("projected area = %v, want %v", a, want)
+	}
+	if f.Properties["projection"] != "proj.LambertAzimuthal" {
+		t.Errorf("properties = %v", f.Properties)
+	}
+}
+
+func TestDegenerateFeature(t *testing.T) {
+	for _, hull := range [][]concaveman.Point{nil, {{1, 1}, {1, 1}}, {{0, 0}, {1, 1}, {0, 0}}} {
+		var buf bytes.Buffer
+		if err := geojson.WriteHull(&buf, hull, len(hull), concaveman.Options{}); err != nil {
+			t.Fatal(err)
+		}
+		if !strings.Contains(buf.String(), `"geometry":null`) {
+			t.Errorf("hull %v: %s", hull, buf.String())
+		}
+	}
+
+	square := concaveman.Polygon{{{0, 0}, {1, 0}, {1, 1}, {0, 1}, {0, 0}}}
+	f, err := geojson.MultiPolygonFeature([]concaveman.Polygon{square, {{{5, 5}, {5, 5}}}}, 6, concaveman.Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(f.Geometry.Coordinates) != "[[[[0,0],[1,0],[1,1],[0,1],[0,0]]]]" || f.Properties["area"] != 1.0 {
+		t.Errorf("MultiPolygonFeature() = %s, %v", f.Geometry.Coordinates, f.Properties)
+	}
+}
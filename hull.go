@@ -0,0 +1,751 @@
+package concaveman
+
+import (
+	"context"
+	"fmt"
+	"math"
+
+	"github.com/wsw0108/concaveman-go/rbush"
+)
+
+// Hull keeps the state of a concave hull computation between calls, so that
+// it can be refined progressively without recomputing the convex hull and
+// reloading the point index.
+type Hull struct {
+	// points not on the hull
+	tree pointIndex
+	// whether tree is a static index, which cannot take new points
+	static bool
+	// hull edges
+	segTree *rbush.Tree[*node]
+	// the last node of the initial convex hull; the ring starts from here
+	last *node
+	// +1 if the interior lies where cross(a, b, p) > 0 for a hull edge (a,b), else -1
+	inner float64
+	// the settings of the last refinement, reused to repair the hull
+	opt     Options
+	refined bool
+	// the remaining points when they are too degenerate to form a hull
+	pending []indexedPoint
+	// the index given to the next added point
+	next int
+	// the starting convex hull and the quadrilateral used to cull points for it
+	convex []indexedPoint
+	cull   []indexedPoint
+	// how distances are measured
+	metric metric
+	// the input coordinates of the points by number, when the hull works on
+	// projected or unwrapped ones, and the mapping of input coordinates to
+	// those; both are nil when the hull works on the input coordinates
+	input   []Point
+	toPlane func(p Point) Point
+}
+
+// NewHull starts a hull from the convex hull of the points. It returns one of
+// the errors documented on ConcavemanE if the input is degenerate.
+func NewHull(points []Point) (*Hull, error) {
+	return NewHullOptions(points, Options{})
+}
+
+// NewHullOptions is like NewHull but reads the points as Concaveman does with
+// the Geodesic and Projection options. The hull is computed in the projected
+// or unwrapped plane, while all points it returns, and those given to
+// AddPoints and RemovePoints, are in input coordinates. StaticIndex holds until
+// a point is added inside the hull, which moves the points to a dynamic index
+// once. The other options are given to Refine.
+func NewHullOptions(points []Point, opt Options) (*Hull, error) {
+	plane, toPlane := planeOf(points, opt)
+	if _, err := validate(plane); err != nil {
+		return nil, err
+	}
+	h := newHull(plane, opt.StaticIndex)
+	if toPlane != nil {
+		h.input = append([]Point(nil), points...)
+		h.toPlane = toPlane
+	}
+	if opt.Geodesic && opt.Projection == nil {
+		h.metric = sphere{}
+	}
+	return h, nil
+}
+
+func newHull(points []Point, static bool) *Hull {
+	return newIndexedHull(indexPoints(points), len(points), static)
+}
+
+// indexPoints numbers the points by their position
+func indexPoints(points []Point) []indexedPoint {
+	ips := make([]indexedPoint, len(points))
+	for i, p := range points {
+		ips[i] = indexedPoint{p, i}
+	}
+	return ips
+}
+
+func newIndexedHull(points []indexedPoint, next int, static bool) *Hull {
+	// start with a convex hull of the points
+	hull, cull := fastConvexHull(points)
+	return newConvexHull(points, hull, cull, next, static)
+}
+
+// newConvexHull starts a hull from the given convex hull of the points and
+// the quadrilateral used to cull points for it
+func newConvexHull(points, hull, cull []indexedPoint, next int, static bool) *Hull {
+	// index the points with an R-tree
+	tree := newPointIndex(points, next, static)
+
+	// turn the convex hull into a linked list
+	var last *node
+	for _, ip := range hull {
+		tree.Remove(ip)
+		last = insertNode(ip, last)
+	}
+
+	// index the segments with an R-tree (for intersection checks)
+	// segTree := &rtree.RTreeG[*node]{}
+	segTree := rbush.NewTree(16, (*node).Rect)
+	n := last
+	for {
+		n = n.next
+		updateBBox(n)
+		// segTree.Insert([2]float64{n.minX, n.minY}, [2]float64{n.maxX, n.maxY}, n)
+		segTree.Insert(n)
+		if n == last {
+			break
+		}
+	}
+
+	convex := append(append([]indexedPoint(nil), hull...), hull[0])
+
+	h := &Hull{
+		tree:    tree,
+		static:  static,
+		segTree: segTree,
+		last:    last,
+		next:    next,
+		convex:  convex,
+		cull:    append(cull, cull[0]),
+		metric:  planar{},
+	}
+	h.inner = 1
+	if RingArea(h.Points()) > 0 {
+		h.inner = -1
+	}
+	return h
+}
+
+// Refine digs the hull further inward with the given concavity and length
+// threshold (see Options) and returns the resulting ring. Refinement only ever
+// tightens the hull, so calling it with a looser setting than a previous call
+// has no effect.
+func (h *Hull) Refine(concavity, lengthThreshold float64) []Point {
+	_ = h.refine(context.Background(), Options{
+		Concavity:       concavity,
+		LengthThreshold: lengthThreshold,
+	})
+	return h.Points()
+}
+
+// RefineContext is like Refine but takes all Options and stops early when ctx
+// is done, returning ctx.Err(). The hull stays usable after an early stop.
+// opt.Observer only sees this refinement: the edges dug again by later calls
+// to AddPoints and RemovePoints are not reported to it.
+func (h *Hull) RefineContext(ctx context.Context, opt Options) ([]Point, error) {
+	err := h.refine(ctx, opt)
+	return h.Points(), err
+}
+
+func (h *Hull) refine(ctx context.Context, opt Options) error {
+	// the observer and the other settings of this call are not kept
+	h.opt = Options{
+		Concavity:       opt.Concavity,
+		LengthThreshold: opt.LengthThreshold,
+	}
+	h.refined = true
+	if h.last == nil {
+		return nil
+	}
+	return h.dig(ctx, h.edges(), opt)
+}
+
+// edges returns the nodes of the ring, each standing for the edge to its successor
+func (h *Hull) edges() []*node {
+	var edges []*node
+	n := h.last
+	for {
+		n = n.next
+		edges = append(edges, n)
+		if n == h.last {
+			break
+		}
+	}
+	return edges
+}
+
+// dig processes the queued edges, flexing them inward as long as the concavity
+// measure allows
+func (h *Hull) dig(ctx context.Context, queue []*node, opt Options) error {
+	// a relative measure of concavity; higher value means simpler hull
+	concavity := math.Max(0, opt.Concavity)
+	// when a segment goes below this length threshold, it won't be drilled down further
+	lengthThreshold := opt.LengthThreshold
+
+	tree := h.tree
+	segTree := h.segTree
+	observer := opt.Observer
+	m := h.metric
+
+	sqConcavity := concavity * concavity
+	sqLenThreshold := lengthThreshold * lengthThreshold
+
+	// process edges one by one
+	for iter := 0; len(queue) > 0; iter++ {
+		if opt.MaxIterations > 0 && iter >= opt.MaxIterations {
+			break
+		}
+		if iter%ctxCheckInterval == 0 {
+			if err := ctx.Err(); err != nil {
+				return err
+			}
+		}
+
+		node := queue[0]
+		queue = queue[1:]
+		a := node.p
+		b := node.next.p
+		if observer != nil {
+			observer.OnEdgeDequeued(a, b)
+		}
+
+		// skip the edge if it's already short enough
+		sqLen := m.sqDist(a, b)
+		if sqLen < sqLenThreshold {
+			continue
+		}
+
+		maxSqLen := sqLen / sqConcavity
+
+		// find the best connection point for the current edge to flex inward to
+		p, ok, err := findCandidate(ctx, tree, node.prev.p, a, b, node.next.next.p, maxSqLen, segTree, m, observer)
+		if err != nil {
+			return err
+		}
+
+		// if we found a connection and it satisfies our concavity measure
+		if ok && math.Min(m.sqDist(p.p, a), m.sqDist(p.p, b)) <= maxSqLen {
+			if observer != nil {
+				observer.OnPointInserted(p.p, a, b)
+			}
+
+			// connect the edge endpoints through this point and add 2 new edges to the queue
+			queue = append(queue, node)
+			queue = append(queue, insertNode(p, node))
+
+			// update point and segment indexes
+			tree.Remove(p)
+			// segTree.Delete([2]float64{node.minX, node.minY}, [2]float64{node.maxX, node.maxY}, node)
+			segTree.Remove(node)
+			n1 := updateBBox(node)
+			n2 := updateBBox(node.next)
+			// segTree.Insert([2]float64{n1.minX, n1.minY}, [2]float64{n1.maxX, n1.maxY}, n1)
+			// segTree.Insert([2]float64{n2.minX, n2.minY}, [2]float64{n2.maxX, n2.maxY}, n2)
+			segTree.Insert(n1)
+			segTree.Insert(n2)
+		} else if ok && observer != nil {
+			observer.OnCandidateRejected(p.p, RejectConcavity)
+		}
+	}
+
+	return nil
+}
+
+// Points returns the current hull as a closed ring. If too few points remain
+// to form a hull, it returns the fallback result documented on ConcavemanE.
+func (h *Hull) Points() []Point {
+	if h.input != nil {
+		return pick(h.input, h.Indices())
+	}
+	if h.last == nil {
+		pending := coords(h.pending)
+		indices, _ := validate(pending)
+		return pick(pending, indices)
+	}
+
+	// convert the resulting hull linked list to an array of points
+	node := h.last
+	var concave []Point
+	for {
+		concave = append(concave, node.p)
+		node = node.next
+		if node == h.last {
+			break
+		}
+	}
+
+	concave = append(concave, node.p)
+
+	return concave
+}
+
+// ConvexHull returns the convex hull the concave hull was started from, as a
+// closed ring. It changes only when the hull has to be rebuilt.
+func (h *Hull) ConvexHull() []Point {
+	return h.output(h.convex)
+}
+
+// CullingQuad returns the quadrilateral formed by the leftmost, topmost,
+// rightmost and bottommost points, as a closed ring. The points inside it
+// were skipped when computing the convex hull.
+func (h *Hull) CullingQuad() []Point {
+	return h.output(h.cull)
+}
+
+// output returns the points in input coordinates
+func (h *Hull) output(points []indexedPoint) []Point {
+	if h.input == nil {
+		return coords(points)
+	}
+	result := make([]Point, len(points))
+	for i, ip := range points {
+		result[i] = h.input[ip.i]
+	}
+	return result
+}
+
+// Indices returns the current hull as a closed ring of point indices. Points
+// passed to NewHull keep their position in the input, and points added later
+// are numbered on from there in the order they were added.
+func (h *Hull) Indices() []int {
+	if h.last == nil {
+		indices, _ := validate(coords(h.pending))
+		if indices == nil {
+			return nil
+		}
+		result := make([]int, len(indices))
+		for i, j := range indices {
+			result[i] = h.pending[j].i
+		}
+		return result
+	}
+
+	node := h.last
+	var concave []int
+	for {
+		concave = append(concave, node.i)
+		node = node.next
+		if node == h.last {
+			break
+		}
+	}
+
+	concave = append(concave, node.i)
+
+	return concave
+}
+
+// Polygon returns the current hull as ConcavemanPolygon does, with holes
+// where an empty region inside the hull can hold a circle of area minHoleArea
+// (see Options.MinHoleArea). A minHoleArea of 0 or less leaves out the holes.
+func (h *Hull) Polygon(minHoleArea float64) Polygon {
+	ring := h.Points()
+	if ring == nil {
+		return nil
+	}
+	polygon := Polygon{ring}
+	if h.last == nil || minHoleArea <= 0 {
+		return polygon
+	}
+
+	// the hull vertices come first
+	points := h.allPoints()
+	plane := coords(points)
+	if _, ok := h.metric.(sphere); ok {
+		plane = localPlane(plane)
+	}
+	n := len(ring) - 1
+	outer := append(plane[:n:n], plane[0])
+	for _, hole := range findHoles(plane, outer, minHoleArea) {
+		ips := make([]indexedPoint, len(hole))
+		for i, j := range hole {
+			ips[i] = points[j]
+		}
+		polygon = append(polygon, h.output(ips))
+	}
+	return polygon
+}
+
+func coords(points []indexedPoint) []Point {
+	result := make([]Point, len(points))
+	for i, ip := range points {
+		result[i] = ip.p
+	}
+	return result
+}
+
+// AddPoints adds points to the hull. Points falling inside the hull only cause
+// the nearby edges to be dug again, while points outside are attached to the
+// closest edge they can be connected to. It returns ErrNonFinite, leaving the
+// hull untouched, if a point has a NaN or infinite coordinate.
+func (h *Hull) AddPoints(points []Point) error {
+	for i, p := range points {
+		if !isFinite(p[0]) || !isFinite(p[1]) {
+			return fmt.Errorf("%w at index %d", ErrNonFinite, i)
+		}
+	}
+	if h.toPlane != nil {
+		h.input = append(h.input, points...)
+		points = h.plane(points)
+	}
+	ips := make([]indexedPoint, len(points))
+	for i, p := range points {
+		ips[i] = indexedPoint{p, h.next}
+		h.next++
+	}
+	if h.last == nil {
+		return h.rebuild(append(h.pending, ips...))
+	}
+
+	var queue []*node
+	for i, ip := range ips {
+		if h.contains(ip.p) {
+			tree, ok := h.tree.(*rbush.Tree[indexedPoint])
+			if !ok {
+				// a static index cannot take the point, so start over
+				// with a dynamic one rather than on every such point
+				h.static = false
+				return h.rebuild(append(h.allPoints(), ips[i:]...))
+			}
+			tree.Insert(ip)
+			queue = append(queue, h.affectedEdges(ip.p)...)
+			continue
+		}
+		n, ok := h.attach(ip)
+		if !ok {
+			return h.rebuild(append(h.allPoints(), ips[i:]...))
+		}
+		queue = append(queue, n, n.next)
+	}
+	return h.repair(queue)
+}
+
+// RemovePoints removes points from the hull, matching them by their
+// coordinates; points that are not part of the hull are ignored. Removing a
+// hull vertex reconnects its neighbours and reattaches the points left
+// outside. If too few points remain to form a hull, it returns one of the
+// errors documented on ConcavemanE and Points reports the fallback result.
+func (h *Hull) RemovePoints(points []Point) error {
+	if h.toPlane != nil {
+		points = h.plane(points)
+	}
+	if h.last == nil {
+		return h.rebuild(removePoints(h.pending, points))
+	}
+
+	var queue []*node
+	for i, p := range points {
+		if h.removeInner(p) {
+			queue = append(queue, h.affectedEdges(p)...)
+			continue
+		}
+		v := h.findNode(p)
+		if v == nil {
+			continue
+		}
+		prev := v.prev
+		outside, ok := h.removeNode(v)
+		if !ok {
+			return h.rebuild(removePoints(h.allPoints(), points[i:]))
+		}
+		queue = append(queue, prev)
+		queue = append(queue, h.affectedEdges(p)...)
+		for j, q := range outside {
+			n, ok := h.attach(q)
+			if !ok {
+				return h.rebuild(removePoints(append(h.allPoints(), outside[j:]...), points[i+1:]))
+			}
+			queue = append(queue, n, n.next)
+		}
+	}
+	return h.repair(queue)
+}
+
+// plane maps points in input coordinates to those of the hull
+func (h *Hull) plane(points []Point) []Point {
+	result := make([]Point, len(points))
+	for i, p := range points {
+		result[i] = h.toPlane(p)
+	}
+	return result
+}
+
+// repair digs the given edges again with the settings of the last refinement
+func (h *Hull) repair(queue []*node) error {
+	if !h.refined || len(queue) == 0 {
+		return nil
+	}
+	// an edge may have been dropped from the ring since it was queued
+	seen := make(map[*node]bool, len(queue))
+	edges := queue[:0]
+	for _, n := range queue {
+		if n.next != nil && !seen[n] {
+			seen[n] = true
+			edges = append(edges, n)
+		}
+	}
+	return h.dig(context.Background(), edges, h.opt)
+}
+
+// rebuild starts over from the given points and refines again if needed
+func (h *Hull) rebuild(points []indexedPoint) error {
+	keep := Hull{
+		opt:     h.opt,
+		refined: h.refined,
+		next:    h.next,
+		static:  h.static,
+		metric:  h.metric,
+		input:   h.input,
+		toPlane: h.toPlane,
+	}
+	if _, err := validate(coords(points)); err != nil {
+		*h = keep
+		h.pending = points
+		return err
+	}
+	*h = *newIndexedHull(points, keep.next, keep.static)
+	h.opt, h.refined, h.metric = keep.opt, keep.refined, keep.metric
+	h.input, h.toPlane = keep.input, keep.toPlane
+	if h.refined {
+		return h.dig(context.Background(), h.edges(), h.opt)
+	}
+	return nil
+}
+
+// allPoints returns the hull vertices followed by the points inside the hull
+func (h *Hull) allPoints() []indexedPoint {
+	var points []indexedPoint
+	for _, n := range h.edges() {
+		points = append(points, indexedPoint{n.p, n.i})
+	}
+	everywhere := [2]float64{math.Inf(-1), math.Inf(-1)}
+	h.tree.Search(everywhere, [2]float64{math.Inf(+1), math.Inf(+1)}, func(ip indexedPoint) bool {
+		points = append(points, ip)
+		return true
+	})
+	return points
+}
+
+// removePoints removes one occurrence of each of the given points
+func removePoints(points []indexedPoint, remove []Point) []indexedPoint {
+	counts := make(map[Point]int, len(remove))
+	for _, p := range remove {
+		counts[p]++
+	}
+	result := make([]indexedPoint, 0, len(points))
+	for _, ip := range points {
+		if counts[ip.p] > 0 {
+			counts[ip.p]--
+			continue
+		}
+		result = append(result, ip)
+	}
+	return result
+}
+
+// affectedEdges returns the edges that may flex inward to the point p
+func (h *Hull) affectedEdges(p Point) []*node {
+	if !h.refined {
+		return nil
+	}
+	concavity := math.Max(0, h.opt.Concavity)
+	sqConcavity := concavity * concavity
+	sqLenThreshold := h.opt.LengthThreshold * h.opt.LengthThreshold
+	m := h.metric
+	near := m.segment(p, p)
+
+	// an edge may flex to p if p is closer to it than its length over the
+	// concavity, so boxes of edges too short to reach p are skipped
+	var edges []*node
+	h.segTree.NearestIter(func(min, max [2]float64) float64 {
+		sqSpan := m.sqSpan(min, max)
+		if sqSpan >= sqLenThreshold && sqEdgeBoxDist(near, m, min, max) <= sqSpan/sqConcavity {
+			return 0
+		}
+		return math.Inf(+1)
+	}, func(n *node) float64 {
+		sqLen := m.sqDist(n.p, n.next.p)
+		if sqLen >= sqLenThreshold && m.segment(n.p, n.next.p).sqDist(p) <= sqLen/sqConcavity {
+			return 0
+		}
+		return math.Inf(+1)
+	}, 0, func(n *node, _ float64) bool {
+		edges = append(edges, n)
+		return true
+	})
+	return edges
+}
+
+// sqEdgeBoxDist returns a lower bound of the squared distance from a point,
+// given as the segment from it to itself, to the edges with both ends in the
+// box (min, max). No point of an edge is farther from its ends than its
+// length, which holds for great-circle arcs bulging out of the box as well.
+func sqEdgeBoxDist(point segment, m metric, min, max [2]float64) float64 {
+	d := math.Max(0, math.Sqrt(point.sqBoxDist(min, max))-math.Sqrt(m.sqSpan(min, max)))
+	return d * d
+}
+
+// contains reports whether p is inside or on the boundary of the hull
+func (h *Hull) contains(p Point) bool {
+	inside := false
+	onEdge := false
+	h.segTree.Search(p, [2]float64{math.Inf(+1), p[1]}, func(edge *node) bool {
+		a := edge.p
+		b := edge.next.p
+		if cross(a, b, p) == 0 && p[0] >= edge.minX && p[0] <= edge.maxX &&
+			p[1] >= edge.minY && p[1] <= edge.maxY {
+			onEdge = true
+			return false
+		}
+		if (a[1] > p[1]) != (b[1] > p[1]) && p[0] < (b[0]-a[0])*(p[1]-a[1])/(b[1]-a[1])+a[0] {
+			inside = !inside
+		}
+		return true
+	})
+	return inside || onEdge
+}
+
+// outside reports whether p lies on the outer side of the hull edge (a,b)
+func (h *Hull) outside(a, b, p Point) bool {
+	return cross(a, b, p)*h.inner < 0
+}
+
+// attach connects a point outside the hull to the closest edge that it can be
+// connected to without introducing self-intersections, and returns the node
+// of that edge's start
+func (h *Hull) attach(ip indexedPoint) (*node, bool) {
+	p := ip.p
+	m := h.metric
+	near := m.segment(p, p)
+
+	// try the edges from the closest one
+	var n *node
+	h.segTree.NearestIter(func(min, max [2]float64) float64 {
+		return sqEdgeBoxDist(near, m, min, max)
+	}, func(edge *node) float64 {
+		return m.segment(edge.p, edge.next.p).sqDist(p)
+	}, math.Inf(+1), func(edge *node, _ float64) bool {
+		a := edge.p
+		b := edge.next.p
+		if !h.outside(a, b, p) {
+			return true
+		}
+		if crossesEdges(a, p, h.segTree, edge.prev, edge, edge.next) ||
+			crossesEdges(p, b, h.segTree, edge.prev, edge, edge.next) {
+			return true
+		}
+		n = edge
+		return false
+	})
+	if n == nil {
+		return nil, false
+	}
+	h.segTree.Remove(n)
+	insertNode(ip, n)
+	h.segTree.Insert(updateBBox(n))
+	h.segTree.Insert(updateBBox(n.next))
+	return n, true
+}
+
+// check if the segment (p,q) crosses any hull edge except the skipped ones
+func crossesEdges(p, q Point, segTree *rbush.Tree[*node], skip ...*node) bool {
+	min := [2]float64{math.Min(p[0], q[0]), math.Min(p[1], q[1])}
+	max := [2]float64{math.Max(p[0], q[0]), math.Max(p[1], q[1])}
+	found := false
+	segTree.Search(min, max, func(edge *node) bool {
+		for _, n := range skip {
+			if edge == n {
+				return true
+			}
+		}
+		if intersects(edge.p, edge.next.p, p, q) {
+			found = true
+			return false
+		}
+		return true
+	})
+	return found
+}
+
+// removeInner removes p from the points inside the hull, if it is one of them
+func (h *Hull) removeInner(p Point) bool {
+	var found indexedPoint
+	ok := false
+	h.tree.Search(p, p, func(ip indexedPoint) bool {
+		if ip.p == p {
+			found, ok = ip, true
+		}
+		return !ok
+	})
+	if ok {
+		h.tree.Remove(found)
+	}
+	return ok
+}
+
+// findNode returns the hull vertex at p, if any
+func (h *Hull) findNode(p Point) *node {
+	var found *node
+	h.segTree.Search(p, p, func(n *node) bool {
+		if n.p == p {
+			found = n
+			return false
+		}
+		return true
+	})
+	return found
+}
+
+// removeNode drops the vertex v from the ring, joining its neighbours, and
+// returns the points that are left outside the hull as a result
+func (h *Hull) removeNode(v *node) ([]indexedPoint, bool) {
+	prev := v.prev
+	next := v.next
+	if next.next == prev || crossesEdges(prev.p, next.p, h.segTree, prev.prev, prev, v, next) {
+		return nil, false
+	}
+
+	// points in the triangle cut off by the new edge
+	var outside []indexedPoint
+	if h.outside(prev.p, next.p, v.p) {
+		min := [2]float64{
+			math.Min(prev.p[0], math.Min(v.p[0], next.p[0])),
+			math.Min(prev.p[1], math.Min(v.p[1], next.p[1])),
+		}
+		max := [2]float64{
+			math.Max(prev.p[0], math.Max(v.p[0], next.p[0])),
+			math.Max(prev.p[1], math.Max(v.p[1], next.p[1])),
+		}
+		h.tree.Search(min, max, func(q indexedPoint) bool {
+			if h.outside(prev.p, next.p, q.p) &&
+				!h.outside(prev.p, v.p, q.p) &&
+				!h.outside(v.p, next.p, q.p) {
+				outside = append(outside, q)
+			}
+			return true
+		})
+	}
+
+	h.segTree.Remove(prev)
+	h.segTree.Remove(v)
+	prev.next = next
+	next.prev = prev
+	// mark v as dropped for repair
+	v.prev, v.next = nil, nil
+	h.segTree.Insert(updateBBox(prev))
+	if h.last == v {
+		h.last = prev
+	}
+	for _, q := range outside {
+		h.tree.Remove(q)
+	}
+	return outside, true
+}
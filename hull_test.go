@@ -0,0 +1,198 @@
+package concaveman_test
+
+import (
+	"errors"
+	"math"
+	"reflect"
+	"testing"
+
+	"github.com/wsw0108/concaveman-go"
+)
+
+func TestHullRefine(t *testing.T) {
+	h, err := concaveman.NewHull(g_points)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result := h.Refine(2, 0); !reflect.DeepEqual(result, g_hull) {
+		t.Error("TestHullRefine: default")
+	}
+
+	h, _ = concaveman.NewHull(g_points)
+	if result := h.Refine(3, 0.01); !reflect.DeepEqual(result, g_hull2) {
+		t.Error("TestHullRefine: tuned")
+	}
+}
+
+func TestHullProgressiveRefine(t *testing.T) {
+	h, _ := concaveman.NewHull(g_points)
+	prev := len(h.Points())
+	for _, concavity := range []float64{20, 10, 5, 2, 1} {
+		result := h.Refine(concavity, 0)
+		if len(result) < prev {
+			t.Errorf("TestHullProgressiveRefine: concavity %v shrank the hull to %d points", concavity, len(result))
+		}
+		prev = len(result)
+	}
+	if prev <= len(g_hull) {
+		t.Errorf("TestHullProgressiveRefine: expected more than %d points, got %d", len(g_hull), prev)
+	}
+}
+
+func TestNewHullDegenerate(t *testing.T) {
+	_, err := concaveman.NewHull([]concaveman.Point{{0, 0}, {1, 1}})
+	if !errors.Is(err, concaveman.ErrTooFewPoints) {
+		t.Errorf("TestNewHullDegenerate: error = %v", err)
+	}
+}
+
+// checkHull verifies that the ring is simple, that its vertices are all among
+// the points and that it encloses the points in contain
+func checkHull(t *testing.T, name string, ring, points, contain []concaveman.Point) {
+	t.Helper()
+	if len(ring) < 4 || ring[0] != ring[len(ring)-1] {
+		t.Fatalf("%s: not a closed ring: %v", name, ring)
+	}
+	all := make(map[concaveman.Point]bool)
+	for _, p := range points {
+		all[p] = true
+	}
+	vertices := make(map[concaveman.Point]bool)
+	for _, p := range ring {
+		if !all[p] {
+			t.Errorf("%s: unknown vertex %v", name, p)
+		}
+		vertices[p] = true
+	}
+	for _, p := range contain {
+		if !vertices[p] && !onRing(p, ring) && !concaveman.PointInPolygon(p, ring) {
+			t.Errorf("%s: point %v outside the hull", name, p)
+		}
+	}
+	n := len(ring) - 1
+	for i := 0; i < n; i++ {
+		for j := i + 2; j < n; j++ {
+			if i == 0 && j == n-1 {
+				continue
+			}
+			if segmentsCross(ring[i], ring[i+1], ring[j], ring[j+1]) {
+				t.Errorf("%s: edges %d and %d cross", name, i, j)
+			}
+		}
+	}
+}
+
+func onRing(p concaveman.Point, ring []concaveman.Point) bool {
+	for i := 0; i+1 < len(ring); i++ {
+		a, b := ring[i], ring[i+1]
+		if (b[0]-a[0])*(p[1]-a[1]) == (b[1]-a[1])*(p[0]-a[0]) &&
+			math.Min(a[0], b[0]) <= p[0] && p[0] <= math.Max(a[0], b[0]) &&
+			math.Min(a[1], b[1]) <= p[1] && p[1] <= math.Max(a[1], b[1]) {
+			return true
+		}
+	}
+	return false
+}
+
+func segmentsCross(a, b, c, d concaveman.Point) bool {
+	orient := func(p, q, r concaveman.Point) float64 {
+		return (q[0]-p[0])*(r[1]-p[1]) - (q[1]-p[1])*(r[0]-p[0])
+	}
+	d1 := orient(a, b, c)
+	d2 := orient(a, b, d)
+	d3 := orient(c, d, a)
+	d4 := orient(c, d, b)
+	return ((d1 > 0 && d2 < 0) || (d1 < 0 && d2 > 0)) &&
+		((d3 > 0 && d4 < 0) || (d3 < 0 && d4 > 0))
+}
+
+func TestHullAddPoints(t *testing.T) {
+	h, _ := concaveman.NewHull(g_points[:500])
+	h.Refine(2, 0)
+	checkHull(t, "initial", h.Points(), g_points[:500], nil)
+
+	for i := 500; i < len(g_points); i += 50 {
+		if err := h.AddPoints(g_points[i : i+50]); err != nil {
+			t.Fatal(err)
+		}
+		checkHull(t, "add", h.Points(), g_points[:i+50], nil)
+	}
+
+	outer := []concaveman.Point{{-122.5, 37.2}, {-121.5, 37.9}}
+	if err := h.AddPoints(outer); err != nil {
+		t.Fatal(err)
+	}
+	all := append(append([]concaveman.Point{}, g_points...), outer...)
+	checkHull(t, "outer", h.Points(), all, outer)
+
+	if err := h.AddPoints([]concaveman.Point{{math.NaN(), 0}}); !errors.Is(err, concaveman.ErrNonFinite) {
+		t.Errorf("TestHullAddPoints: error = %v", err)
+	}
+}
+
+func TestHullRemovePoints(t *testing.T) {
+	h, _ := concaveman.NewHull(g_points)
+	h.Refine(2, 0)
+	points := append([]concaveman.Point{}, g_points...)
+
+	// peel off hull vertices and interior points alternately
+	for k := 0; k < 50; k++ {
+		ring := h.Points()
+		remove := []concaveman.Point{ring[k%(len(ring)-1)], points[len(points)/2]}
+		if err := h.RemovePoints(remove); err != nil {
+			t.Fatal(err)
+		}
+		for _, p := range remove {
+			for i, q := range points {
+				if p == q {
+					points = append(points[:i], points[i+1:]...)
+					break
+				}
+			}
+		}
+		checkHull(t, "remove", h.Points(), points, nil)
+	}
+
+	err := h.RemovePoints(points[2:])
+	if !errors.Is(err, concaveman.ErrTooFewPoints) && !errors.Is(err, concaveman.ErrCollinear) {
+		t.Errorf("TestHullRemovePoints: error = %v", err)
+	}
+	if err := h.AddPoints(g_points); err != nil {
+		t.Fatal(err)
+	}
+	checkHull(t, "restored", h.Points(), g_points, nil)
+}
+
+func TestHullIndices(t *testing.T) {
+	h, _ := concaveman.NewHull(g_points[:500])
+	h.Refine(2, 0)
+	if err := h.AddPoints(g_points[500:]); err != nil {
+		t.Fatal(err)
+	}
+	ring := h.Points()
+	indices := h.Indices()
+	if len(ring) != len(indices) {
+		t.Fatalf("TestHullIndices: %d points, %d indices", len(ring), len(indices))
+	}
+	for i, j := range indices {
+		if g_points[j] != ring[i] {
+			t.Errorf("TestHullIndices: index %d", i)
+		}
+	}
+}
+
+func BenchmarkHullAddPoints(b *testing.B) {
+	points := randomPoints(100000, 1)
+	h, _ := concaveman.NewHull(points)
+	h.Refine(2, 0)
+	// some of them fall outside the hull
+	added := randomPoints(b.N, 2)
+	for i := range added {
+		added[i][0] *= 2
+	}
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		h.AddPoints(added[i : i+1])
+	}
+}
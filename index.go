@@ -0,0 +1,68 @@
+package concaveman
+
+import (
+	"github.com/wsw0108/concaveman-go/flatbush"
+	"github.com/wsw0108/concaveman-go/rbush"
+)
+
+// pointIndex holds the points inside the hull
+type pointIndex interface {
+	Search(min, max [2]float64, iter func(ip indexedPoint) bool) bool
+	NearestIter(boxDist func(min, max [2]float64) float64, itemDist func(ip indexedPoint) float64, maxDist float64, iter func(ip indexedPoint, dist float64) bool) bool
+	Remove(ip indexedPoint)
+}
+
+var (
+	_ pointIndex = (*rbush.Tree[indexedPoint])(nil)
+	_ pointIndex = (*staticIndex)(nil)
+)
+
+// newPointIndex indexes the points, numbered below next, with a dynamic
+// R-tree or, if static, with a packed one that can only shrink
+func newPointIndex(points []indexedPoint, next int, static bool) pointIndex {
+	if !static {
+		tree := rbush.NewTree(16, indexedPoint.Rect)
+		tree.Load(append([]indexedPoint(nil), points...))
+		return tree
+	}
+	s := &staticIndex{
+		index:  flatbush.New(len(points), 16),
+		points: points,
+		ids:    make([]int, next),
+	}
+	for _, ip := range points {
+		s.ids[ip.i] = s.index.Add(ip.p, ip.p)
+	}
+	s.index.Finish()
+	return s
+}
+
+// staticIndex adapts a flatbush index of points to pointIndex
+type staticIndex struct {
+	index  *flatbush.Index
+	points []indexedPoint
+	// the id in the index of each point, by point number
+	ids []int
+}
+
+func (s *staticIndex) Search(min, max [2]float64, iter func(ip indexedPoint) bool) bool {
+	return s.index.Search(min, max, func(id int) bool {
+		return iter(s.points[id])
+	})
+}
+
+func (s *staticIndex) NearestIter(boxDist func(min, max [2]float64) float64, itemDist func(ip indexedPoint) float64, maxDist float64, iter func(ip indexedPoint, dist float64) bool) bool {
+	return s.index.NearestIter(boxDist, func(id int) float64 {
+		return itemDist(s.points[id])
+	}, maxDist, func(id int, dist float64) bool {
+		return iter(s.points[id], dist)
+	})
+}
+
+func (s *staticIndex) Remove(ip indexedPoint) {
+	if ip.i < len(s.ids) {
+		if id := s.ids[ip.i]; s.points[id] == ip {
+			s.index.Remove(id)
+		}
+	}
+}
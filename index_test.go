@@ -0,0 +1,86 @@
+package concaveman_test
+
+import (
+	"context"
+	"math/rand"
+	"reflect"
+	"testing"
+
+	"github.com/wsw0108/concaveman-go"
+	"github.com/wsw0108/concaveman-go/internal/testutil"
+)
+
+func TestStaticIndex(t *testing.T) {
+	result := concaveman.Concaveman(g_points, concaveman.Options{Concavity: 2, StaticIndex: true})
+	if !reflect.DeepEqual(result, g_hull) {
+		t.Error("TestStaticIndex: default hull differs")
+	}
+	result = concaveman.Concaveman(g_points, concaveman.Options{Concavity: 3, LengthThreshold: 0.01, StaticIndex: true})
+	if !reflect.DeepEqual(result, g_hull2) {
+		t.Error("TestStaticIndex: tuned hull differs")
+	}
+}
+
+func randomPoints(n int, seed int64) []concaveman.Point {
+	return testutil.NormalPoints[concaveman.Point](rand.New(rand.NewSource(seed)), n)
+}
+
+func TestStaticIndexRandom(t *testing.T) {
+	for seed := int64(1); seed <= 5; seed++ {
+		points := randomPoints(5000, seed)
+		want := concaveman.ConcavemanIndices(points)
+		got := concaveman.ConcavemanIndices(points, concaveman.Options{Concavity: 2, StaticIndex: true})
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("TestStaticIndexRandom: seed %d: hulls differ", seed)
+		}
+	}
+}
+
+func TestStaticIndexHull(t *testing.T) {
+	points := randomPoints(5000, 1)
+	opt := concaveman.Options{Concavity: 2, StaticIndex: true}
+	h, err := concaveman.NewHullOptions(points, opt)
+	if err != nil {
+		t.Fatal(err)
+	}
+	h.RefineContext(context.Background(), opt)
+	if got, want := h.Indices(), concaveman.ConcavemanIndices(points, opt); !reflect.DeepEqual(got, want) {
+		t.Errorf("TestStaticIndexHull: hull differs")
+	}
+
+	// only the first point inside the hull rebuilds it, after which adding
+	// points costs as much as with a dynamic index
+	dynamic, _ := concaveman.NewHullOptions(points, concaveman.Options{Concavity: 2})
+	dynamic.RefineContext(context.Background(), opt)
+	want := addAllocs(t, dynamic)
+	if got := addAllocs(t, h); got > 2*want {
+		t.Errorf("TestStaticIndexHull: %v allocations per added point, want about %v", got, want)
+	}
+}
+
+func addAllocs(t *testing.T, h *concaveman.Hull) float64 {
+	k := 0
+	return testing.AllocsPerRun(100, func() {
+		k++
+		if err := h.AddPoints([]concaveman.Point{{float64(k) * 1e-4, 0}}); err != nil {
+			t.Fatal(err)
+		}
+	})
+}
+
+func benchmarkConcaveman(b *testing.B, opt concaveman.Options) {
+	points := randomPoints(200000, 1)
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		concaveman.Concaveman(points, opt)
+	}
+}
+
+func BenchmarkConcaveman(b *testing.B) {
+	benchmarkConcaveman(b, concaveman.Options{Concavity: 2})
+}
+
+func BenchmarkConcavemanStaticIndex(b *testing.B) {
+	benchmarkConcaveman(b, concaveman.Options{Concavity: 2, StaticIndex: true})
+}
@@ -0,0 +1,74 @@
+// Package testutil generates the random inputs shared by the tests of the
+// spatial indexes and of the hull.
+package testutil
+
+import (
+	"math/rand"
+	"sort"
+)
+
+// Points returns n points drawn uniformly from the square [0, size)².
+func Points[P ~[2]float64](r *rand.Rand, n int, size float64) []P {
+	points := make([]P, n)
+	for i := range points {
+		points[i] = P{r.Float64() * size, r.Float64() * size}
+	}
+	return points
+}
+
+// NormalPoints returns n points with standard normal coordinates.
+func NormalPoints[P ~[2]float64](r *rand.Rand, n int) []P {
+	points := make([]P, n)
+	for i := range points {
+		points[i] = P{r.NormFloat64(), r.NormFloat64()}
+	}
+	return points
+}
+
+// Box is a box with an id, which is its index in the slice returned by Boxes.
+type Box struct {
+	ID       int
+	Min, Max [2]float64
+}
+
+// Rect returns the corners of the box.
+func (b Box) Rect() (min, max [2]float64) {
+	return b.Min, b.Max
+}
+
+// Boxes returns n boxes with their lower corner in [0, 100)² and sides of up
+// to 5.
+func Boxes(r *rand.Rand, n int) []Box {
+	boxes := make([]Box, n)
+	for i := range boxes {
+		x, y := r.Float64()*100, r.Float64()*100
+		boxes[i] = Box{i, [2]float64{x, y}, [2]float64{x + r.Float64()*5, y + r.Float64()*5}}
+	}
+	return boxes
+}
+
+// Overlapping returns the sorted ids of the boxes overlapping (min, max) by
+// brute force, leaving out those in removed.
+func Overlapping(boxes []Box, removed map[int]bool, min, max [2]float64) []int {
+	var ids []int
+	for _, b := range boxes {
+		if !removed[b.ID] && b.Min[0] <= max[0] && b.Max[0] >= min[0] && b.Min[1] <= max[1] && b.Max[1] >= min[1] {
+			ids = append(ids, b.ID)
+		}
+	}
+	sort.Ints(ids)
+	return ids
+}
+
+// EqualIDs reports whether a and b hold the same ids in the same order.
+func EqualIDs(a, b []int) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
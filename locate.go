@@ -0,0 +1,90 @@
+package concaveman
+
+import (
+	"fmt"
+	"math"
+)
+
+// Location is the position of a point relative to a polygon.
+type Location int
+
+const (
+	Outside Location = iota
+	Inside
+	OnBoundary
+)
+
+func (l Location) String() string {
+	switch l {
+	case Outside:
+		return "outside"
+	case Inside:
+		return "inside"
+	case OnBoundary:
+		return "on boundary"
+	}
+	return fmt.Sprintf("Location(%d)", int(l))
+}
+
+// Locate tells whether the point p lies inside the polygon, outside of it or
+// on the boundary of its outer ring or one of its holes. Points in a hole are
+// outside. Unlike PointInPolygon, the answer is exact, including for points on
+// edges and vertices. Rings may be closed or open and wind either way.
+func Locate(p Point, poly Polygon) Location {
+	if len(poly) == 0 {
+		return Outside
+	}
+	if loc := locateRing(p, poly[0]); loc != Inside {
+		return loc
+	}
+	for _, hole := range poly[1:] {
+		switch locateRing(p, hole) {
+		case Inside:
+			return Outside
+		case OnBoundary:
+			return OnBoundary
+		}
+	}
+	return Inside
+}
+
+// LocateMulti is like Locate for the union of the polygons, which are
+// expected not to overlap. A point on the boundary of one polygon and inside
+// another one is inside.
+func LocateMulti(p Point, polys []Polygon) Location {
+	result := Outside
+	for _, poly := range polys {
+		switch Locate(p, poly) {
+		case Inside:
+			return Inside
+		case OnBoundary:
+			result = OnBoundary
+		}
+	}
+	return result
+}
+
+// locateRing counts the crossings of a ray going right from p with the ring,
+// deciding exactly on which side of an edge p lies
+func locateRing(p Point, ring []Point) Location {
+	inside := false
+	j := len(ring) - 1
+	for i := range ring {
+		a := ring[j]
+		b := ring[i]
+		j = i
+		o := cross(a, b, p)
+		if o == 0 && p[0] >= math.Min(a[0], b[0]) && p[0] <= math.Max(a[0], b[0]) &&
+			p[1] >= math.Min(a[1], b[1]) && p[1] <= math.Max(a[1], b[1]) {
+			return OnBoundary
+		}
+		// cross is negative when p is left of the edge
+		if (a[1] > p[1]) != (b[1] > p[1]) && (o < 0) == (b[1] > a[1]) {
+			inside = !inside
+		}
+	}
+	if inside {
+		return Inside
+	}
+	return Outside
+}
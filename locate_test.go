@@ -0,0 +1,79 @@
+package concaveman_test
+
+import (
+	"math"
+	"testing"
+
+	"github.com/wsw0108/concaveman-go"
+)
+
+func TestLocate(t *testing.T) {
+	square := concaveman.Polygon{
+		{{0, 0}, {0, 10}, {10, 10}, {10, 0}, {0, 0}},
+		{{2, 2}, {5, 2}, {5, 5}, {2, 5}, {2, 2}},
+	}
+	// an open, counterclockwise ring with a slanted edge
+	slanted := concaveman.Polygon{{{0, 0}, {3, 1}, {0, 3}}}
+
+	tests := []struct {
+		name string
+		p    concaveman.Point
+		poly concaveman.Polygon
+		want concaveman.Location
+	}{
+		{"inside", concaveman.Point{1, 1}, square, concaveman.Inside},
+		{"outside", concaveman.Point{11, 1}, square, concaveman.Outside},
+		{"in hole", concaveman.Point{3, 3}, square, concaveman.Outside},
+		{"on edge", concaveman.Point{0, 5}, square, concaveman.OnBoundary},
+		{"on vertex", concaveman.Point{10, 10}, square, concaveman.OnBoundary},
+		{"on hole edge", concaveman.Point{5, 3}, square, concaveman.OnBoundary},
+		{"on hole vertex", concaveman.Point{2, 2}, square, concaveman.OnBoundary},
+		{"level with vertex", concaveman.Point{-1, 10}, square, concaveman.Outside},
+		{"level with hole vertex", concaveman.Point{1, 2}, square, concaveman.Inside},
+		{"on extended edge", concaveman.Point{0, 11}, square, concaveman.Outside},
+		{"on slanted edge", concaveman.Point{1.5, 0.5}, slanted, concaveman.OnBoundary},
+		{"just above slanted edge", concaveman.Point{1.5, math.Nextafter(0.5, 1)}, slanted, concaveman.Inside},
+		{"just below slanted edge", concaveman.Point{1.5, math.Nextafter(0.5, 0)}, slanted, concaveman.Outside},
+		{"closing edge", concaveman.Point{0, 1}, slanted, concaveman.OnBoundary},
+		{"empty", concaveman.Point{0, 0}, nil, concaveman.Outside},
+	}
+	for _, tt := range tests {
+		if got := concaveman.Locate(tt.p, tt.poly); got != tt.want {
+			t.Errorf("%s: got %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestLocateMulti(t *testing.T) {
+	polys := []concaveman.Polygon{
+		{{{0, 0}, {0, 1}, {1, 1}, {1, 0}, {0, 0}}},
+		{{{1, 0}, {1, 1}, {2, 1}, {2, 0}, {1, 0}}},
+		{{{5, 5}, {5, 6}, {6, 6}, {6, 5}, {5, 5}}},
+	}
+	tests := []struct {
+		p    concaveman.Point
+		want concaveman.Location
+	}{
+		{concaveman.Point{0.5, 0.5}, concaveman.Inside},
+		{concaveman.Point{5.5, 5.5}, concaveman.Inside},
+		{concaveman.Point{1, 0.5}, concaveman.OnBoundary},
+		{concaveman.Point{3, 3}, concaveman.Outside},
+	}
+	for _, tt := range tests {
+		if got := concaveman.LocateMulti(tt.p, polys); got != tt.want {
+			t.Errorf("LocateMulti(%v) = %v, want %v", tt.p, got, tt.want)
+		}
+	}
+}
+
+func TestLocateHull(t *testing.T) {
+	// hull vertices are on the boundary
+	for _, p := range g_hull {
+		if got := concaveman.Locate(p, concaveman.Polygon{g_hull}); got != concaveman.OnBoundary {
+			t.Fatalf("TestLocateHull: %v is %v", p, got)
+		}
+	}
+	if s := concaveman.OnBoundary.String(); s != "on boundary" {
+		t.Errorf("TestLocateHull: got %q", s)
+	}
+}
@@ -0,0 +1,59 @@
+package concaveman
+
+// metric measures the squared distances that drive the digging loop
+type metric interface {
+	// sqDist returns the squared distance between two points
+	sqDist(p1, p2 Point) float64
+	// segment prepares distance queries against the segment (a,b)
+	segment(a, b Point) segment
+	// bounds returns a box holding every point within distance of p
+	bounds(p Point, distance float64) node
+	// sqSpan returns an upper bound of the squared distance between two
+	// points in the box (min, max)
+	sqSpan(min, max [2]float64) float64
+}
+
+// segment measures squared distances to a fixed segment
+type segment interface {
+	// sqDist returns the squared distance from p to the segment
+	sqDist(p Point) float64
+	// sqBoxDist returns a lower bound of the squared distance from the
+	// segment to any point in the box (min, max)
+	sqBoxDist(min, max [2]float64) float64
+}
+
+// planar is the Euclidean metric of the plane
+type planar struct{}
+
+func (planar) sqDist(p1, p2 Point) float64 {
+	return getSqDist(p1, p2)
+}
+
+func (planar) segment(a, b Point) segment {
+	return &planarSegment{a, b}
+}
+
+func (planar) bounds(p Point, distance float64) node {
+	return node{
+		minX: p[0] - distance,
+		minY: p[1] - distance,
+		maxX: p[0] + distance,
+		maxY: p[1] + distance,
+	}
+}
+
+func (planar) sqSpan(min, max [2]float64) float64 {
+	return getSqDist(min, max)
+}
+
+type planarSegment struct {
+	a, b Point
+}
+
+func (s *planarSegment) sqDist(p Point) float64 {
+	return sqSegDist(p, s.a, s.b)
+}
+
+func (s *planarSegment) sqBoxDist(min, max [2]float64) float64 {
+	return sqSegBoxDist(s.a, s.b, min, max)
+}
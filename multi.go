@@ -0,0 +1,83 @@
+package concaveman
+
+import (
+	"fmt"
+
+	"github.com/wsw0108/concaveman-go/rbush"
+)
+
+// ConcavemanMulti splits the points into clusters, where points closer than
+// distance to each other belong to the same cluster, and returns the concave
+// hull of every cluster as computed by ConcavemanPolygon. Clusters are ordered
+// by their first point in the input; those too small to form a hull yield the
+// fallback rings documented on ConcavemanE. It returns nil if a point has a
+// NaN or infinite coordinate.
+func ConcavemanMulti(points []Point, distance float64, opts ...Options) []Polygon {
+	polygons, _ := ConcavemanMultiE(points, distance, opts...)
+	return polygons
+}
+
+// ConcavemanMultiE is like ConcavemanMulti but returns ErrNonFinite, and no
+// hulls, if a point has a NaN or infinite coordinate.
+func ConcavemanMultiE(points []Point, distance float64, opts ...Options) ([]Polygon, error) {
+	for i, p := range points {
+		if !isFinite(p[0]) || !isFinite(p[1]) {
+			return nil, fmt.Errorf("%w at index %d", ErrNonFinite, i)
+		}
+	}
+
+	var m metric = planar{}
+	plane := points
+	switch opt := getOptions(opts); {
+	case opt.Projection != nil:
+		plane = project(points, opt.Projection)
+	case opt.Geodesic:
+		m = sphere{}
+		plane = unwrap(points)
+	}
+	var polygons []Polygon
+	for _, cluster := range clusters(plane, distance, m) {
+		polygons = append(polygons, ConcavemanPolygon(pick(points, cluster), opts...))
+	}
+	return polygons, nil
+}
+
+// clusters groups the points into connected components, linking the points
+// that are within distance of each other as measured by m, and returns them as
+// point indices
+func clusters(points []Point, distance float64, m metric) [][]int {
+	items := make([]indexedPoint, len(points))
+	for i, p := range points {
+		items[i] = indexedPoint{p, i}
+	}
+	tree := rbush.NewTree(16, indexedPoint.Rect)
+	tree.Load(items)
+
+	sqDist := distance * distance
+	visited := make([]bool, len(points))
+	var result [][]int
+	var stack []int
+	for i := range points {
+		if visited[i] {
+			continue
+		}
+		visited[i] = true
+		cluster := []int{i}
+		stack = append(stack[:0], i)
+		for len(stack) > 0 {
+			p := points[stack[len(stack)-1]]
+			stack = stack[:len(stack)-1]
+			min, max := m.bounds(p, distance).Rect()
+			tree.Search(min, max, func(ip indexedPoint) bool {
+				if !visited[ip.i] && m.sqDist(p, ip.p) <= sqDist {
+					visited[ip.i] = true
+					cluster = append(cluster, ip.i)
+					stack = append(stack, ip.i)
+				}
+				return true
+			})
+		}
+		result = append(result, cluster)
+	}
+	return result
+}
@@ -0,0 +1,43 @@
+package concaveman_test
+
+import (
+	"errors"
+	"math"
+	"reflect"
+	"testing"
+
+	"github.com/wsw0108/concaveman-go"
+)
+
+func TestConcavemanMulti(t *testing.T) {
+	// the sample points shifted far apart, plus a lone point
+	var points []concaveman.Point
+	for _, p := range g_points {
+		points = append(points, p, concaveman.Point{p[0] + 10, p[1]})
+	}
+	points = append(points, concaveman.Point{0, 0})
+
+	polygons := concaveman.ConcavemanMulti(points, 0.01)
+	if len(polygons) != 3 {
+		t.Fatalf("TestConcavemanMulti: got %d clusters", len(polygons))
+	}
+	for _, poly := range polygons[:2] {
+		if len(poly) != 1 || len(poly[0]) != len(g_hull) {
+			t.Errorf("TestConcavemanMulti: got %d points, want %d", len(poly[0]), len(g_hull))
+		}
+	}
+	if !reflect.DeepEqual(polygons[2], concaveman.Polygon{{{0, 0}, {0, 0}}}) {
+		t.Errorf("TestConcavemanMulti: lone point = %v", polygons[2])
+	}
+
+	polygons = concaveman.ConcavemanMulti(points, 1000)
+	if len(polygons) != 1 {
+		t.Errorf("TestConcavemanMulti: got %d clusters, want 1", len(polygons))
+	}
+
+	points = append(points, concaveman.Point{math.Inf(1), 0})
+	polygons, err := concaveman.ConcavemanMultiE(points, 0.01)
+	if !errors.Is(err, concaveman.ErrNonFinite) || polygons != nil {
+		t.Errorf("TestConcavemanMulti: non-finite point gave %d clusters, error %v", len(polygons), err)
+	}
+}
@@ -0,0 +1,43 @@
+package concaveman
+
+// RejectReason tells why a point was not connected to the edge being dug.
+type RejectReason int
+
+const (
+	// RejectAdjacentEdge means the point is at least as close to one of the
+	// edges adjacent to the current edge as to the edge itself.
+	RejectAdjacentEdge RejectReason = iota + 1
+	// RejectIntersection means connecting the point to the edge endpoints
+	// would make the hull intersect itself.
+	RejectIntersection
+	// RejectConcavity means the point is the best candidate for the edge but
+	// too far from its endpoints for the concavity measure.
+	RejectConcavity
+)
+
+func (r RejectReason) String() string {
+	switch r {
+	case RejectAdjacentEdge:
+		return "adjacent edge"
+	case RejectIntersection:
+		return "intersection"
+	case RejectConcavity:
+		return "concavity"
+	}
+	return "unknown"
+}
+
+// Observer receives the steps of the loop that digs the hull edges inward.
+// Its methods are called synchronously from the computation. Points are given
+// in the coordinates the hull is computed in: projected with a Projection,
+// and with longitudes unwrapped across the antimeridian with Geodesic.
+type Observer interface {
+	// OnEdgeDequeued is called when the edge (a,b) is taken from the queue.
+	OnEdgeDequeued(a, b Point)
+	// OnCandidateRejected is called when the point p is skipped for the
+	// current edge.
+	OnCandidateRejected(p Point, reason RejectReason)
+	// OnPointInserted is called when the point p is inserted into the hull
+	// between a and b.
+	OnPointInserted(p Point, a, b Point)
+}
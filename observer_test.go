@@ -0,0 +1,99 @@
+package concaveman_test
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"github.com/wsw0108/concaveman-go"
+)
+
+type countingObserver struct {
+	dequeued int
+	rejected map[concaveman.RejectReason]int
+	inserted []concaveman.Point
+}
+
+func (o *countingObserver) OnEdgeDequeued(a, b concaveman.Point) {
+	o.dequeued++
+}
+
+func (o *countingObserver) OnCandidateRejected(p concaveman.Point, reason concaveman.RejectReason) {
+	o.rejected[reason]++
+}
+
+func (o *countingObserver) OnPointInserted(p concaveman.Point, a, b concaveman.Point) {
+	o.inserted = append(o.inserted, p)
+}
+
+func TestObserver(t *testing.T) {
+	o := &countingObserver{rejected: make(map[concaveman.RejectReason]int)}
+	result := concaveman.Concaveman(g_points, concaveman.Options{
+		Concavity: 2,
+		Observer:  o,
+	})
+	if !reflect.DeepEqual(result, g_hull) {
+		t.Error("TestObserver: the observer changed the hull")
+	}
+
+	h, _ := concaveman.NewHull(g_points)
+	convex := len(h.ConvexHull()) - 1
+	if len(o.inserted) != len(g_hull)-1-convex {
+		t.Errorf("TestObserver: %d points inserted, want %d", len(o.inserted), len(g_hull)-1-convex)
+	}
+	if o.dequeued < len(g_hull)-1 {
+		t.Errorf("TestObserver: only %d edges dequeued", o.dequeued)
+	}
+	for _, reason := range []concaveman.RejectReason{
+		concaveman.RejectAdjacentEdge,
+		concaveman.RejectIntersection,
+		concaveman.RejectConcavity,
+	} {
+		if o.rejected[reason] == 0 {
+			t.Errorf("TestObserver: no rejection for %v", reason)
+		}
+	}
+}
+
+func TestObserverScope(t *testing.T) {
+	o := &countingObserver{rejected: make(map[concaveman.RejectReason]int)}
+	h, _ := concaveman.NewHull(g_points)
+	h.RefineContext(context.Background(), concaveman.Options{
+		Concavity: 2,
+		Observer:  o,
+	})
+	dequeued := o.dequeued
+	if dequeued == 0 {
+		t.Fatal("TestObserverScope: the observer saw nothing")
+	}
+	if err := h.AddPoints([]concaveman.Point{{g_hull[0][0] - 0.01, g_hull[0][1]}}); err != nil {
+		t.Fatal(err)
+	}
+	if o.dequeued != dequeued {
+		t.Errorf("TestObserverScope: AddPoints reported %d edges to the observer", o.dequeued-dequeued)
+	}
+}
+
+// mirror is a projection mirroring the x axis
+type mirror struct{}
+
+func (mirror) Forward(x, y float64) (float64, float64) { return -x, y }
+
+func TestObserverProjection(t *testing.T) {
+	o := &countingObserver{rejected: make(map[concaveman.RejectReason]int)}
+	concaveman.Concaveman(g_points, concaveman.Options{
+		Concavity:  2,
+		Projection: mirror{},
+		Observer:   o,
+	})
+	if len(o.inserted) == 0 {
+		t.Fatal("TestObserverProjection: no points inserted")
+	}
+	// the input points all lie west of Greenwich
+	for _, p := range o.inserted {
+		if p[0] < 0 {
+			t.Errorf("TestObserverProjection: inserted point %v is not projected", p)
+			break
+		}
+	}
+}
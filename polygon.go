@@ -0,0 +1,297 @@
+package concaveman
+
+import (
+	"context"
+	"math"
+
+	"github.com/wsw0108/concaveman-go/delaunay"
+	"github.com/wsw0108/concaveman-go/rbush"
+)
+
+// Polygon is a polygon given as its outer ring followed by its holes, if any.
+// All rings are closed, and holes wind opposite to the outer ring.
+type Polygon [][]Point
+
+// ConcavemanPolygon is like Concaveman but returns a polygon which, when
+// Options.MinHoleArea is set, has the large empty regions inside the hull cut
+// out as holes. A hole is bounded by input points and never touches the outer
+// ring or encloses other points. Degenerate input yields a polygon made of the
+// fallback ring documented on ConcavemanE, or nil if there is none.
+func ConcavemanPolygon(points []Point, opts ...Options) Polygon {
+	polygon, _ := ConcavemanPolygonE(points, opts...)
+	return polygon
+}
+
+// ConcavemanPolygonE is like ConcavemanPolygon but also returns the error
+// described on ConcavemanE for degenerate input.
+func ConcavemanPolygonE(points []Point, opts ...Options) (Polygon, error) {
+	opt := getOptions(opts)
+	indices, err := concaveman(context.Background(), points, opt)
+	if indices == nil {
+		return nil, err
+	}
+	polygon := Polygon{pick(points, indices)}
+	if err != nil || opt.MinHoleArea <= 0 {
+		return polygon, err
+	}
+	plane := points
+	switch {
+	case opt.Projection != nil:
+		plane = project(points, opt.Projection)
+	case opt.Geodesic:
+		plane = localPlane(unwrap(points))
+	}
+	for _, hole := range findHoles(plane, pick(plane, indices), opt.MinHoleArea) {
+		polygon = append(polygon, pick(points, hole))
+	}
+	return polygon, nil
+}
+
+// findHoles looks for empty regions inside the outer ring by merging the
+// Delaunay triangles whose empty circumcircle is at least minArea large, and
+// returns them as closed rings of point indices
+func findHoles(points []Point, outer []Point, minArea float64) [][]int {
+	t := delaunay.New(points)
+	n := t.Len()
+
+	radius := math.Sqrt(minArea / math.Pi)
+	empty := make([]bool, n)
+	for i := 0; i < n; i++ {
+		empty[i] = t.Circumradius(i) >= radius
+	}
+
+	labels, count := components(t, empty)
+	areas := make([]float64, count)
+	valid := make([]bool, count)
+	for i := range valid {
+		valid[i] = true
+	}
+	for i := 0; i < n; i++ {
+		c := labels[i]
+		if c < 0 {
+			continue
+		}
+		tr := t.Triangle(i)
+		areas[c] += math.Abs(RingArea([]Point{tr[0], tr[1], tr[2], tr[0]}))
+		for _, e := range delaunay.EdgesOfTriangle(i) {
+			// regions open to the outside of the point set are no holes
+			if t.Halfedges[e] == -1 {
+				valid[c] = false
+			}
+		}
+	}
+
+	loops, loopLabels := boundaryLoops(t, labels)
+	loopCount := make([]int, count)
+	for _, c := range loopLabels {
+		loopCount[c]++
+	}
+
+	onOuter := make(map[Point]bool, len(outer))
+	for _, p := range outer {
+		onOuter[p] = true
+	}
+	segTree := ringTree(outer)
+	clockwise := RingArea(outer) < 0
+
+	var holes [][]int
+	for k, loop := range loops {
+		c := loopLabels[k]
+		// islands of points inside an empty region are not supported
+		if !valid[c] || areas[c] < minArea || loopCount[c] != 1 {
+			continue
+		}
+		hole := make([]Point, 0, len(loop)+1)
+		ring := make([]int, 0, len(loop)+1)
+		seen := make(map[int]bool, len(loop))
+		ok := true
+		for _, i := range loop {
+			p := points[i]
+			if seen[i] || onOuter[p] || !PointInPolygon(p, outer) {
+				ok = false
+				break
+			}
+			seen[i] = true
+			hole = append(hole, p)
+			ring = append(ring, i)
+		}
+		if !ok {
+			continue
+		}
+		hole = append(hole, hole[0])
+		ring = append(ring, ring[0])
+		for i := 0; i+1 < len(hole); i++ {
+			if crossesEdges(hole[i], hole[i+1], segTree) {
+				ok = false
+				break
+			}
+		}
+		if !ok {
+			continue
+		}
+		if (RingArea(hole) < 0) == clockwise {
+			reverse(ring)
+		}
+		holes = append(holes, ring)
+	}
+	return holes
+}
+
+// components labels the connected sets of triangles selected by in, and
+// returns the labels (-1 for triangles not selected) and the number of sets
+func components(t *delaunay.Triangulation[Point], in []bool) ([]int, int) {
+	labels := make([]int, len(in))
+	for i := range labels {
+		labels[i] = -1
+	}
+	count := 0
+	var stack []int
+	for i := range in {
+		if !in[i] || labels[i] >= 0 {
+			continue
+		}
+		labels[i] = count
+		stack = append(stack[:0], i)
+		for len(stack) > 0 {
+			tr := stack[len(stack)-1]
+			stack = stack[:len(stack)-1]
+			for _, e := range delaunay.EdgesOfTriangle(tr) {
+				o := t.Halfedges[e]
+				if o == -1 {
+					continue
+				}
+				adj := delaunay.TriangleOfEdge(o)
+				if in[adj] && labels[adj] < 0 {
+					labels[adj] = count
+					stack = append(stack, adj)
+				}
+			}
+		}
+		count++
+	}
+	return labels, count
+}
+
+// boundaryLoops traces the boundaries of the labelled triangle sets, and
+// returns them as open rings of point indices along with their labels
+func boundaryLoops(t *delaunay.Triangulation[Point], labels []int) ([][]int, []int) {
+	boundary := func(e int) bool {
+		c := labels[delaunay.TriangleOfEdge(e)]
+		o := t.Halfedges[e]
+		return c >= 0 && (o == -1 || labels[delaunay.TriangleOfEdge(o)] != c)
+	}
+
+	visited := make([]bool, len(t.Triangles))
+	var loops [][]int
+	var loopLabels []int
+	for start := range t.Triangles {
+		if visited[start] || !boundary(start) {
+			continue
+		}
+		var loop []int
+		e := start
+		for !visited[e] {
+			visited[e] = true
+			loop = append(loop, t.Triangles[e])
+			// turn around the end point of e until reaching the next boundary edge
+			n := delaunay.NextHalfedge(e)
+			for !boundary(n) {
+				n = delaunay.NextHalfedge(t.Halfedges[n])
+			}
+			e = n
+		}
+		loops = append(loops, loop)
+		loopLabels = append(loopLabels, labels[delaunay.TriangleOfEdge(start)])
+	}
+	return loops, loopLabels
+}
+
+// ringTree indexes the edges of a closed ring for intersection checks
+func ringTree(ring []Point) *rbush.Tree[*node] {
+	var last *node
+	for i := 0; i+1 < len(ring); i++ {
+		last = insertNode(indexedPoint{ring[i], i}, last)
+	}
+	segTree := rbush.NewTree(16, (*node).Rect)
+	n := last
+	for {
+		n = n.next
+		segTree.Insert(updateBBox(n))
+		if n == last {
+			break
+		}
+	}
+	return segTree
+}
+
+func reverse[T any](ring []T) {
+	for i, j := 0, len(ring)-1; i < j; i, j = i+1, j-1 {
+		ring[i], ring[j] = ring[j], ring[i]
+	}
+}
+
+// RingArea returns the signed planar area of a closed ring, positive for
+// counterclockwise rings with the y axis pointing up. Hulls are clockwise.
+func RingArea(ring []Point) float64 {
+	var sum float64
+	for i := 0; i+1 < len(ring); i++ {
+		sum += ring[i][0]*ring[i+1][1] - ring[i+1][0]*ring[i][1]
+	}
+	return sum / 2
+}
+
+// Area returns the area of the polygon, holes excluded, interpreting the
+// points like Concaveman does with the given options: with a Projection it is
+// the planar area of the projected rings, in the squared units of the
+// projection; when Geodesic it is the area on the sphere in square meters;
+// otherwise it is the planar area in squared input units.
+func (p Polygon) Area(opts ...Options) float64 {
+	opt := getOptions(opts)
+	var a float64
+	for i, ring := range p {
+		var ra float64
+		switch {
+		case opt.Projection != nil:
+			ra = math.Abs(RingArea(project(ring, opt.Projection)))
+		case opt.Geodesic:
+			ra = sphericalRingArea(unwrap(ring))
+		default:
+			ra = math.Abs(RingArea(ring))
+		}
+		if i == 0 {
+			a += ra
+		} else {
+			a -= ra
+		}
+	}
+	return a
+}
+
+// sphericalRingArea returns the area in square meters of a closed ring of
+// unwrapped [lon, lat] points on the sphere. Each edge contributes the signed
+// area of the zone between it and the south pole, R²·Δλ·(1 + sin φ), with
+// sin φ averaged over its two ends; this is the approximation of Chamberlain
+// and Duquette used by geojson-area and turf.
+func sphericalRingArea(ring []Point) float64 {
+	var sum float64
+	for i := 0; i+1 < len(ring); i++ {
+		p, q := ring[i], ring[i+1]
+		sum += (q[0] - p[0]) * deg2rad * (2 + math.Sin(p[1]*deg2rad) + math.Sin(q[1]*deg2rad))
+	}
+	return math.Abs(sum) * earthRadius * earthRadius / 2
+}
+
+// Rewind returns a copy of the polygon with the outer ring counterclockwise,
+// with the y axis pointing up, and the holes clockwise, as RFC 7946 asks for
+// GeoJSON. If clockwise is true, the orientations are swapped.
+func (p Polygon) Rewind(clockwise bool) Polygon {
+	result := make(Polygon, len(p))
+	for i, ring := range p {
+		ring = append([]Point(nil), ring...)
+		if (RingArea(ring) < 0) == (i == 0) != clockwise {
+			reverse(ring)
+		}
+		result[i] = ring
+	}
+	return result
+}
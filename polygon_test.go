@@ -0,0 +1,110 @@
+package concaveman_test
+
+import (
+	"errors"
+	"math"
+	"reflect"
+	"testing"
+
+	"github.com/wsw0108/concaveman-go"
+)
+
+// a 21x21 grid with a 10x10 lake in the middle
+func lakeGrid() []concaveman.Point {
+	var points []concaveman.Point
+	for x := 0; x <= 20; x++ {
+		for y := 0; y <= 20; y++ {
+			if x > 5 && x < 15 && y > 5 && y < 15 {
+				continue
+			}
+			points = append(points, concaveman.Point{float64(x), float64(y)})
+		}
+	}
+	return points
+}
+
+func TestConcavemanPolygon(t *testing.T) {
+	poly := concaveman.ConcavemanPolygon(g_points)
+	if len(poly) != 1 || !reflect.DeepEqual(poly[0], g_hull) {
+		t.Error("TestConcavemanPolygon: expected the plain hull")
+	}
+
+	points := lakeGrid()
+	poly = concaveman.ConcavemanPolygon(points, concaveman.Options{
+		Concavity:   2,
+		MinHoleArea: 20,
+	})
+	if len(poly) != 2 {
+		t.Fatalf("TestConcavemanPolygon: expected 1 hole, got %d", len(poly)-1)
+	}
+	outer, hole := poly[0], poly[1]
+	// the lake corners are cut off by small triangles
+	if area := math.Abs(concaveman.RingArea(hole)); area < 90 || area > 100 {
+		t.Errorf("TestConcavemanPolygon: hole area = %v", area)
+	}
+	if (concaveman.RingArea(outer) < 0) == (concaveman.RingArea(hole) < 0) {
+		t.Error("TestConcavemanPolygon: hole winds like the outer ring")
+	}
+	for _, p := range hole {
+		if p[0] < 5 || p[0] > 15 || p[1] < 5 || p[1] > 15 {
+			t.Errorf("TestConcavemanPolygon: hole vertex %v off the lake", p)
+		}
+	}
+
+	poly = concaveman.ConcavemanPolygon(points, concaveman.Options{
+		Concavity:   2,
+		MinHoleArea: 1000,
+	})
+	if len(poly) != 1 {
+		t.Errorf("TestConcavemanPolygon: expected no hole, got %d", len(poly)-1)
+	}
+}
+
+func TestHullPolygon(t *testing.T) {
+	points := lakeGrid()
+	opt := concaveman.Options{Concavity: 2, MinHoleArea: 20}
+	h, err := concaveman.NewHullOptions(points, opt)
+	if err != nil {
+		t.Fatal(err)
+	}
+	h.Refine(opt.Concavity, opt.LengthThreshold)
+	poly := h.Polygon(opt.MinHoleArea)
+	want := concaveman.ConcavemanPolygon(points, opt)
+	if len(poly) != len(want) || !reflect.DeepEqual(poly[0], want[0]) {
+		t.Fatalf("TestHullPolygon: got %v, want %v", poly, want)
+	}
+	if a, b := concaveman.RingArea(poly[1]), concaveman.RingArea(want[1]); a != b {
+		t.Errorf("TestHullPolygon: hole area %v, want %v", a, b)
+	}
+	if poly := h.Polygon(0); len(poly) != 1 {
+		t.Errorf("TestHullPolygon: expected no hole, got %d", len(poly)-1)
+	}
+}
+
+func TestPolygonRewind(t *testing.T) {
+	poly := concaveman.ConcavemanPolygon(lakeGrid(), concaveman.Options{
+		Concavity:   2,
+		MinHoleArea: 20,
+	})
+	for _, clockwise := range []bool{false, true} {
+		rewound := poly.Rewind(clockwise)
+		if (concaveman.RingArea(rewound[0]) < 0) != clockwise || (concaveman.RingArea(rewound[1]) < 0) == clockwise {
+			t.Errorf("TestPolygonRewind: clockwise %v gave the wrong orientations", clockwise)
+		}
+	}
+	want := math.Abs(concaveman.RingArea(poly[0])) - math.Abs(concaveman.RingArea(poly[1]))
+	if a := poly.Area(); a != want || a <= 0 {
+		t.Errorf("TestPolygonRewind: area = %v, want %v", a, want)
+	}
+}
+
+func TestConcavemanPolygonE(t *testing.T) {
+	poly, err := concaveman.ConcavemanPolygonE([]concaveman.Point{{0, 0}, {1, 1}, {2, 2}})
+	if !errors.Is(err, concaveman.ErrCollinear) || !reflect.DeepEqual(poly, concaveman.Polygon{{{0, 0}, {2, 2}, {0, 0}}}) {
+		t.Errorf("TestConcavemanPolygonE: got %v, %v", poly, err)
+	}
+	poly, err = concaveman.ConcavemanPolygonE(g_points)
+	if err != nil || len(poly) != 1 || !reflect.DeepEqual(poly[0], g_hull) {
+		t.Errorf("TestConcavemanPolygonE: error = %v", err)
+	}
+}
@@ -0,0 +1,96 @@
+package predicates
+
+import "math"
+
+// expansion is an exact sum of nonoverlapping components ordered by
+// increasing magnitude, as in Shewchuk's arithmetic. The exact predicates
+// build their determinants from expansions allocated on each call, so they are
+// slow but safe for concurrent use; they only run when the floating-point
+// result is too close to zero to trust.
+type expansion []float64
+
+// twoSum returns a+b rounded and the error of the rounding
+func twoSum(a, b float64) (x, y float64) {
+	x = a + b
+	bvirt := x - a
+	avirt := x - bvirt
+	y = (a - avirt) + (b - bvirt)
+	return x, y
+}
+
+// fastTwoSum is twoSum for |a| >= |b|
+func fastTwoSum(a, b float64) (x, y float64) {
+	x = a + b
+	y = b - (x - a)
+	return x, y
+}
+
+// twoProduct returns a*b rounded and the error of the rounding
+func twoProduct(a, b float64) (x, y float64) {
+	x = a * b
+	y = math.FMA(a, b, -x)
+	return x, y
+}
+
+// diff returns the exact difference a-b
+func diff(a, b float64) expansion {
+	x, y := twoSum(a, -b)
+	if y == 0 {
+		return expansion{x}
+	}
+	return expansion{y, x}
+}
+
+func (e expansion) add(f expansion) expansion {
+	h := make(expansion, len(e)+len(f))
+	return h[:sum(len(e), e, len(f), f, h)]
+}
+
+func (e expansion) sub(f expansion) expansion {
+	return e.add(f.neg())
+}
+
+func (e expansion) neg() expansion {
+	h := make(expansion, len(e))
+	for i, c := range e {
+		h[i] = -c
+	}
+	return h
+}
+
+// scale_expansion_zeroelim routine from original code
+func (e expansion) scale(b float64) expansion {
+	h := make(expansion, 0, 2*len(e))
+	q, hh := twoProduct(e[0], b)
+	if hh != 0 {
+		h = append(h, hh)
+	}
+	for _, c := range e[1:] {
+		p1, p0 := twoProduct(c, b)
+		s, hh := twoSum(q, p0)
+		if hh != 0 {
+			h = append(h, hh)
+		}
+		q, hh = fastTwoSum(p1, s)
+		if hh != 0 {
+			h = append(h, hh)
+		}
+	}
+	if q != 0 || len(h) == 0 {
+		h = append(h, q)
+	}
+	return h
+}
+
+func (e expansion) mul(f expansion) expansion {
+	h := e.scale(f[0])
+	for _, c := range f[1:] {
+		h = h.add(e.scale(c))
+	}
+	return h
+}
+
+// value returns the most significant component, which has the sign of the sum
+func (e expansion) value() float64 {
+	return e[len(e)-1]
+}
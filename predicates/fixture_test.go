@@ -0,0 +1,100 @@
+package predicates_test
+
+import (
+	"bufio"
+	"math/big"
+	"os"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+// fixture is a line of a testdata file: the coordinates passed to a predicate
+// and the sign of the exact result
+type fixture struct {
+	line   string
+	coords []float64
+	sign   int
+}
+
+func readFixtures(t *testing.T, filename string, n int) []fixture {
+	f, err := os.Open(filename)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+
+	var fixtures []fixture
+	s := bufio.NewScanner(f)
+	for s.Scan() {
+		line := s.Text()
+		parts := strings.Split(line, " ")
+		if len(parts) != n+2 {
+			t.Fatalf("%s: bad line %q", filename, line)
+		}
+		coords := make([]float64, n)
+		for i := range coords {
+			coords[i], err = strconv.ParseFloat(parts[i+1], 64)
+			if err != nil {
+				t.Fatal(err)
+			}
+		}
+		sign, err := strconv.Atoi(parts[n+1])
+		if err != nil {
+			t.Fatal(err)
+		}
+		fixtures = append(fixtures, fixture{line, coords, sign})
+	}
+	if err := s.Err(); err != nil {
+		t.Fatal(err)
+	}
+	return fixtures
+}
+
+func sign(v float64) int {
+	switch {
+	case v > 0:
+		return 1
+	case v < 0:
+		return -1
+	}
+	return 0
+}
+
+// exactSign returns the exact sign of the determinant with the rows p - q
+// for the given points p, extended by |p - q|² if lift is set. That is the
+// determinant of InCircle and InSphere when lifted, and of Orient3D otherwise.
+func exactSign(points [][]float64, q []float64, lift bool) int {
+	m := make([][]*big.Rat, len(points))
+	for i, p := range points {
+		l := new(big.Rat)
+		for k := range p {
+			d := new(big.Rat).Sub(new(big.Rat).SetFloat64(p[k]), new(big.Rat).SetFloat64(q[k]))
+			m[i] = append(m[i], d)
+			l.Add(l, new(big.Rat).Mul(d, d))
+		}
+		if lift {
+			m[i] = append(m[i], l)
+		}
+	}
+	return laplace(m).Sign()
+}
+
+func laplace(m [][]*big.Rat) *big.Rat {
+	if len(m) == 1 {
+		return m[0][0]
+	}
+	det := new(big.Rat)
+	for j := range m {
+		minor := make([][]*big.Rat, 0, len(m)-1)
+		for _, row := range m[1:] {
+			minor = append(minor, append(append([]*big.Rat{}, row[:j]...), row[j+1:]...))
+		}
+		term := new(big.Rat).Mul(m[0][j], laplace(minor))
+		if j%2 == 1 {
+			term.Neg(term)
+		}
+		det.Add(det, term)
+	}
+	return det
+}
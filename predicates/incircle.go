@@ -0,0 +1,82 @@
+package predicates
+
+import "math"
+
+const iccerrboundA = (10 + 96*epsilon) * epsilon
+
+// InCircle returns a positive value if the point d lies inside the circle
+// through a, b and c, a negative value if it lies outside, and zero if the
+// four points are cocircular. The sign is reversed unless a, b and c are in
+// counterclockwise order with the y axis pointing up, i.e. unless
+// Orient2D(a, b, c) is negative. The sign is exact; the magnitude
+// approximates the determinant.
+func InCircle(ax, ay, bx, by, cx, cy, dx, dy float64) float64 {
+	adx := ax - dx
+	bdx := bx - dx
+	cdx := cx - dx
+	ady := ay - dy
+	bdy := by - dy
+	cdy := cy - dy
+
+	bdxcdy := bdx * cdy
+	cdxbdy := cdx * bdy
+	alift := adx*adx + ady*ady
+
+	cdxady := cdx * ady
+	adxcdy := adx * cdy
+	blift := bdx*bdx + bdy*bdy
+
+	adxbdy := adx * bdy
+	bdxady := bdx * ady
+	clift := cdx*cdx + cdy*cdy
+
+	det := alift*(bdxcdy-cdxbdy) +
+		blift*(cdxady-adxcdy) +
+		clift*(adxbdy-bdxady)
+
+	permanent := (math.Abs(bdxcdy)+math.Abs(cdxbdy))*alift +
+		(math.Abs(cdxady)+math.Abs(adxcdy))*blift +
+		(math.Abs(adxbdy)+math.Abs(bdxady))*clift
+	errbound := iccerrboundA * permanent
+	if det > errbound || -det > errbound {
+		return det
+	}
+	return incircleExact(ax, ay, bx, by, cx, cy, dx, dy)
+}
+
+// InCircleFast is like InCircle but computes the determinant with plain
+// floating-point arithmetic, so its sign may be wrong for nearly cocircular
+// points.
+func InCircleFast(ax, ay, bx, by, cx, cy, dx, dy float64) float64 {
+	adx := ax - dx
+	ady := ay - dy
+	bdx := bx - dx
+	bdy := by - dy
+	cdx := cx - dx
+	cdy := cy - dy
+
+	ab := adx*bdy - bdx*ady
+	bc := bdx*cdy - cdx*bdy
+	ca := cdx*ady - adx*cdy
+	alift := adx*adx + ady*ady
+	blift := bdx*bdx + bdy*bdy
+	clift := cdx*cdx + cdy*cdy
+
+	return alift*bc + blift*ca + clift*ab
+}
+
+func incircleExact(ax, ay, bx, by, cx, cy, dx, dy float64) float64 {
+	adx, ady := diff(ax, dx), diff(ay, dy)
+	bdx, bdy := diff(bx, dx), diff(by, dy)
+	cdx, cdy := diff(cx, dx), diff(cy, dy)
+
+	alift := adx.mul(adx).add(ady.mul(ady))
+	blift := bdx.mul(bdx).add(bdy.mul(bdy))
+	clift := cdx.mul(cdx).add(cdy.mul(cdy))
+
+	bc := bdx.mul(cdy).sub(cdx.mul(bdy))
+	ca := cdx.mul(ady).sub(adx.mul(cdy))
+	ab := adx.mul(bdy).sub(bdx.mul(ady))
+
+	return alift.mul(bc).add(blift.mul(ca)).add(clift.mul(ab)).value()
+}
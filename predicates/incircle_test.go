@@ -0,0 +1,56 @@
+package predicates_test
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+
+	"github.com/wsw0108/concaveman-go/predicates"
+)
+
+func TestInCircle(t *testing.T) {
+	// (0,0), (1,0), (0,1) are counterclockwise
+	if v := predicates.InCircle(0, 0, 1, 0, 0, 1, 0.5, 0.5); v <= 0 {
+		t.Error("inside")
+	}
+	if v := predicates.InCircle(0, 0, 1, 0, 0, 1, 2, 2); v >= 0 {
+		t.Error("outside")
+	}
+	if v := predicates.InCircle(0, 0, 1, 0, 0, 1, 1, 1); v != 0 {
+		t.Error("cocircular")
+	}
+	if v := predicates.InCircle(0, 0, 0, 1, 1, 0, 0.5, 0.5); v >= 0 {
+		t.Error("clockwise")
+	}
+
+	// 1000 hard fixtures
+	for _, f := range readFixtures(t, "testdata/incircle.txt", 8) {
+		c := f.coords
+		if got := sign(predicates.InCircle(c[0], c[1], c[2], c[3], c[4], c[5], c[6], c[7])); got != f.sign {
+			t.Errorf("%s: got %d", f.line, got)
+		}
+	}
+}
+
+func TestInCircleNearCocircular(t *testing.T) {
+	// points close to the unit circle through (1,0), (0,1) and (-1,0)
+	r := rand.New(rand.NewSource(1))
+	for i := 0; i < 1000; i++ {
+		th := r.Float64() * 2 * math.Pi
+		x := math.Cos(th) + float64(r.Intn(5)-2)*math.Pow(2, -52)
+		y := math.Sin(th)
+		want := exactSign([][]float64{{1, 0}, {0, 1}, {-1, 0}}, []float64{x, y}, true)
+		if got := sign(predicates.InCircle(1, 0, 0, 1, -1, 0, x, y)); got != want {
+			t.Fatalf("(%v, %v): got %d, want %d", x, y, got, want)
+		}
+	}
+}
+
+func TestInCircleFast(t *testing.T) {
+	if v := predicates.InCircleFast(0, 0, 1, 0, 0, 1, 0.5, 0.5); v <= 0 {
+		t.Error("inside")
+	}
+	if v := predicates.InCircleFast(0, 0, 1, 0, 0, 1, 2, 2); v >= 0 {
+		t.Error("outside")
+	}
+}
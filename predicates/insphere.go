@@ -0,0 +1,156 @@
+package predicates
+
+import "math"
+
+const isperrboundA = (16 + 224*epsilon) * epsilon
+
+// InSphere returns a positive value if the point e lies inside the sphere
+// through a, b, c and d, a negative value if it lies outside, and zero if the
+// five points are cospherical. The sign is reversed unless
+// Orient3D(a, b, c, d) is positive, as when a, b and c are counterclockwise
+// with the y axis up, Orient2D(a, b, c) being negative, and d lies below
+// them. The sign is exact; the magnitude
+// approximates the determinant.
+func InSphere(ax, ay, az, bx, by, bz, cx, cy, cz, dx, dy, dz, ex, ey, ez float64) float64 {
+	aex := ax - ex
+	bex := bx - ex
+	cex := cx - ex
+	dex := dx - ex
+	aey := ay - ey
+	bey := by - ey
+	cey := cy - ey
+	dey := dy - ey
+	aez := az - ez
+	bez := bz - ez
+	cez := cz - ez
+	dez := dz - ez
+
+	aexbey := aex * bey
+	bexaey := bex * aey
+	ab := aexbey - bexaey
+	bexcey := bex * cey
+	cexbey := cex * bey
+	bc := bexcey - cexbey
+	cexdey := cex * dey
+	dexcey := dex * cey
+	cd := cexdey - dexcey
+	dexaey := dex * aey
+	aexdey := aex * dey
+	da := dexaey - aexdey
+	aexcey := aex * cey
+	cexaey := cex * aey
+	ac := aexcey - cexaey
+	bexdey := bex * dey
+	dexbey := dex * bey
+	bd := bexdey - dexbey
+
+	abc := aez*bc - bez*ac + cez*ab
+	bcd := bez*cd - cez*bd + dez*bc
+	cda := cez*da + dez*ac + aez*cd
+	dab := dez*ab + aez*bd + bez*da
+
+	alift := aex*aex + aey*aey + aez*aez
+	blift := bex*bex + bey*bey + bez*bez
+	clift := cex*cex + cey*cey + cez*cez
+	dlift := dex*dex + dey*dey + dez*dez
+
+	det := (dlift*abc - clift*dab) + (blift*cda - alift*bcd)
+
+	aezplus := math.Abs(aez)
+	bezplus := math.Abs(bez)
+	cezplus := math.Abs(cez)
+	dezplus := math.Abs(dez)
+	aexbeyplus := math.Abs(aexbey)
+	bexaeyplus := math.Abs(bexaey)
+	bexceyplus := math.Abs(bexcey)
+	cexbeyplus := math.Abs(cexbey)
+	cexdeyplus := math.Abs(cexdey)
+	dexceyplus := math.Abs(dexcey)
+	dexaeyplus := math.Abs(dexaey)
+	aexdeyplus := math.Abs(aexdey)
+	aexceyplus := math.Abs(aexcey)
+	cexaeyplus := math.Abs(cexaey)
+	bexdeyplus := math.Abs(bexdey)
+	dexbeyplus := math.Abs(dexbey)
+	permanent := ((cexdeyplus+dexceyplus)*bezplus+
+		(dexbeyplus+bexdeyplus)*cezplus+
+		(bexceyplus+cexbeyplus)*dezplus)*alift +
+		((dexaeyplus+aexdeyplus)*cezplus+
+			(aexceyplus+cexaeyplus)*dezplus+
+			(cexdeyplus+dexceyplus)*aezplus)*blift +
+		((aexbeyplus+bexaeyplus)*dezplus+
+			(bexdeyplus+dexbeyplus)*aezplus+
+			(dexaeyplus+aexdeyplus)*bezplus)*clift +
+		((bexceyplus+cexbeyplus)*aezplus+
+			(cexaeyplus+aexceyplus)*bezplus+
+			(aexbeyplus+bexaeyplus)*cezplus)*dlift
+	errbound := isperrboundA * permanent
+	if det > errbound || -det > errbound {
+		return det
+	}
+	return insphereExact(ax, ay, az, bx, by, bz, cx, cy, cz, dx, dy, dz, ex, ey, ez)
+}
+
+// InSphereFast is like InSphere but computes the determinant with plain
+// floating-point arithmetic, so its sign may be wrong for nearly cospherical
+// points.
+func InSphereFast(ax, ay, az, bx, by, bz, cx, cy, cz, dx, dy, dz, ex, ey, ez float64) float64 {
+	aex := ax - ex
+	bex := bx - ex
+	cex := cx - ex
+	dex := dx - ex
+	aey := ay - ey
+	bey := by - ey
+	cey := cy - ey
+	dey := dy - ey
+	aez := az - ez
+	bez := bz - ez
+	cez := cz - ez
+	dez := dz - ez
+
+	ab := aex*bey - bex*aey
+	bc := bex*cey - cex*bey
+	cd := cex*dey - dex*cey
+	da := dex*aey - aex*dey
+	ac := aex*cey - cex*aey
+	bd := bex*dey - dex*bey
+
+	abc := aez*bc - bez*ac + cez*ab
+	bcd := bez*cd - cez*bd + dez*bc
+	cda := cez*da + dez*ac + aez*cd
+	dab := dez*ab + aez*bd + bez*da
+
+	alift := aex*aex + aey*aey + aez*aez
+	blift := bex*bex + bey*bey + bez*bez
+	clift := cex*cex + cey*cey + cez*cez
+	dlift := dex*dex + dey*dey + dez*dez
+
+	return (dlift*abc - clift*dab) + (blift*cda - alift*bcd)
+}
+
+func insphereExact(ax, ay, az, bx, by, bz, cx, cy, cz, dx, dy, dz, ex, ey, ez float64) float64 {
+	aex, aey, aez := diff(ax, ex), diff(ay, ey), diff(az, ez)
+	bex, bey, bez := diff(bx, ex), diff(by, ey), diff(bz, ez)
+	cex, cey, cez := diff(cx, ex), diff(cy, ey), diff(cz, ez)
+	dex, dey, dez := diff(dx, ex), diff(dy, ey), diff(dz, ez)
+
+	ab := aex.mul(bey).sub(bex.mul(aey))
+	bc := bex.mul(cey).sub(cex.mul(bey))
+	cd := cex.mul(dey).sub(dex.mul(cey))
+	da := dex.mul(aey).sub(aex.mul(dey))
+	ac := aex.mul(cey).sub(cex.mul(aey))
+	bd := bex.mul(dey).sub(dex.mul(bey))
+
+	abc := aez.mul(bc).sub(bez.mul(ac)).add(cez.mul(ab))
+	bcd := bez.mul(cd).sub(cez.mul(bd)).add(dez.mul(bc))
+	cda := cez.mul(da).add(dez.mul(ac)).add(aez.mul(cd))
+	dab := dez.mul(ab).add(aez.mul(bd)).add(bez.mul(da))
+
+	alift := aex.mul(aex).add(aey.mul(aey)).add(aez.mul(aez))
+	blift := bex.mul(bex).add(bey.mul(bey)).add(bez.mul(bez))
+	clift := cex.mul(cex).add(cey.mul(cey)).add(cez.mul(cez))
+	dlift := dex.mul(dex).add(dey.mul(dey)).add(dez.mul(dez))
+
+	det := dlift.mul(abc).sub(clift.mul(dab))
+	return det.add(blift.mul(cda).sub(alift.mul(bcd))).value()
+}
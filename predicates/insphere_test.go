@@ -0,0 +1,65 @@
+package predicates_test
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+
+	"github.com/wsw0108/concaveman-go/predicates"
+)
+
+func TestInSphere(t *testing.T) {
+	// Orient3D of these is positive
+	a := []float64{0, 0, 0}
+	b := []float64{1, 0, 0}
+	c := []float64{0, 1, 0}
+	d := []float64{0, 0, -1}
+	insphere := func(e ...float64) float64 {
+		return predicates.InSphere(a[0], a[1], a[2], b[0], b[1], b[2], c[0], c[1], c[2], d[0], d[1], d[2], e[0], e[1], e[2])
+	}
+	if v := insphere(0.2, 0.2, -0.2); v <= 0 {
+		t.Error("inside")
+	}
+	if v := insphere(5, 5, 5); v >= 0 {
+		t.Error("outside")
+	}
+	if v := insphere(1, 1, -1); v != 0 {
+		t.Error("cospherical")
+	}
+
+	// 1000 hard fixtures
+	for _, f := range readFixtures(t, "testdata/insphere.txt", 15) {
+		c := f.coords
+		got := sign(predicates.InSphere(c[0], c[1], c[2], c[3], c[4], c[5], c[6], c[7], c[8], c[9], c[10], c[11], c[12], c[13], c[14]))
+		if got != f.sign {
+			t.Errorf("%s: got %d", f.line, got)
+		}
+	}
+}
+
+func TestInSphereNearCospherical(t *testing.T) {
+	// points close to the unit sphere
+	r := rand.New(rand.NewSource(1))
+	a := []float64{1, 0, 0}
+	b := []float64{0, 1, 0}
+	c := []float64{-1, 0, 0}
+	d := []float64{0, 0, 1}
+	for i := 0; i < 300; i++ {
+		e := []float64{r.NormFloat64(), r.NormFloat64(), r.NormFloat64()}
+		l := math.Sqrt(e[0]*e[0] + e[1]*e[1] + e[2]*e[2])
+		for k := range e {
+			e[k] /= l
+		}
+		want := exactSign([][]float64{a, b, c, d}, e, true)
+		got := sign(predicates.InSphere(a[0], a[1], a[2], b[0], b[1], b[2], c[0], c[1], c[2], d[0], d[1], d[2], e[0], e[1], e[2]))
+		if got != want {
+			t.Fatalf("%v: got %d, want %d", e, got, want)
+		}
+	}
+}
+
+func TestInSphereFast(t *testing.T) {
+	if v := predicates.InSphereFast(0, 0, 0, 1, 0, 0, 0, 1, 0, 0, 0, -1, 0.2, 0.2, -0.2); v <= 0 {
+		t.Error("inside")
+	}
+}
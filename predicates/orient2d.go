@@ -1,3 +1,14 @@
+// Package predicates implements Shewchuk's adaptive exact geometric
+// predicates, whose signs are always correct despite floating-point rounding.
+//
+// Sign conventions: Orient2D follows the JavaScript robust-predicates and is
+// positive for clockwise points with the y axis pointing up, which is the
+// negation of Shewchuk's orient2d. InCircle, Orient3D and InSphere keep
+// Shewchuk's signs: for points a, b and c with Orient2D(a, b, c) < 0, that is
+// counterclockwise with the y axis up, InCircle is positive inside their
+// circle, and Orient3D is negative for a point above their plane (on the z
+// side from which they appear counterclockwise), so it has the sign of
+// Orient2D(a, b, c) there.
 package predicates
 
 import "math"
@@ -8,7 +19,8 @@ const (
 	resulterrbound = (3 + 8*epsilon) * epsilon
 )
 
-// fast_expansion_sum_zeroelim routine from oritinal code
+// fast_expansion_sum_zeroelim routine from oritinal code; it never reads past
+// the elen and flen components
 func sum(elen int, e []float64, flen int, f []float64, h []float64) int {
 	var Q, Qnew, hh, bvirt float64
 	enow := e[0]
@@ -18,11 +30,15 @@ func sum(elen int, e []float64, flen int, f []float64, h []float64) int {
 	if (fnow > enow) == (fnow > -enow) {
 		Q = enow
 		eindex++
-		enow = e[eindex]
+		if eindex < elen {
+			enow = e[eindex]
+		}
 	} else {
 		Q = fnow
 		findex++
-		fnow = f[findex]
+		if findex < flen {
+			fnow = f[findex]
+		}
 	}
 	hindex := 0
 	if eindex < elen && findex < flen {
@@ -30,12 +46,16 @@ func sum(elen int, e []float64, flen int, f []float64, h []float64) int {
 			Qnew = enow + Q
 			hh = Q - (Qnew - enow)
 			eindex++
-			enow = e[eindex]
+			if eindex < elen {
+				enow = e[eindex]
+			}
 		} else {
 			Qnew = fnow + Q
 			hh = Q - (Qnew - fnow)
 			findex++
-			fnow = f[findex]
+			if findex < flen {
+				fnow = f[findex]
+			}
 		}
 		Q = Qnew
 		if hh != 0 {
@@ -48,13 +68,17 @@ func sum(elen int, e []float64, flen int, f []float64, h []float64) int {
 				bvirt = Qnew - Q
 				hh = Q - (Qnew - bvirt) + (enow - bvirt)
 				eindex++
-				enow = e[eindex]
+				if eindex < elen {
+					enow = e[eindex]
+				}
 			} else {
 				Qnew = Q + fnow
 				bvirt = Qnew - Q
 				hh = Q - (Qnew - bvirt) + (fnow - bvirt)
 				findex++
-				fnow = f[findex]
+				if findex < flen {
+					fnow = f[findex]
+				}
 			}
 			Q = Qnew
 			if hh != 0 {
@@ -68,7 +92,9 @@ func sum(elen int, e []float64, flen int, f []float64, h []float64) int {
 		bvirt = Qnew - Q
 		hh = Q - (Qnew - bvirt) + (enow - bvirt)
 		eindex++
-		enow = e[eindex]
+		if eindex < elen {
+			enow = e[eindex]
+		}
 		Q = Qnew
 		if hh != 0 {
 			h[hindex] = hh
@@ -80,7 +106,9 @@ func sum(elen int, e []float64, flen int, f []float64, h []float64) int {
 		bvirt = Qnew - Q
 		hh = Q - (Qnew - bvirt) + (fnow - bvirt)
 		findex++
-		fnow = f[findex]
+		if findex < flen {
+			fnow = f[findex]
+		}
 		Q = Qnew
 		if hh != 0 {
 			h[hindex] = hh
@@ -108,15 +136,13 @@ const (
 	ccwerrboundC = (9 + 64*epsilon) * epsilon * epsilon
 )
 
-var (
-	B  [4]float64
-	C1 [8]float64
-	C2 [12]float64
-	D  [16]float64
-	u  [4]float64
-)
-
 func orient2Dadapt(ax, ay, bx, by, cx, cy, detsum float64) float64 {
+	// local buffers keep the predicate safe for concurrent use
+	var B [4]float64
+	var C1 [8]float64
+	var C2 [12]float64
+	var D [16]float64
+	var u [4]float64
 	var acxtail, acytail, bcxtail, bcytail float64
 	var bvirt, c, ahi, alo, bhi, blo, _i, _j, _0, s1, s0, t1, t0, u3 float64
 
@@ -276,6 +302,11 @@ func orient2Dadapt(ax, ay, bx, by, cx, cy, detsum float64) float64 {
 	return D[Dlen-1]
 }
 
+// Orient2D returns a positive value if the points a, b and c are in clockwise
+// order with the y axis pointing up (counterclockwise with the y axis pointing
+// down, as on screen), a negative value if they are in the opposite order, and
+// zero if they are collinear. The sign is exact; the magnitude approximates
+// twice the area of the triangle abc.
 func Orient2D(ax, ay, bx, by, cx, cy float64) float64 {
 	detleft := (ay - cy) * (bx - cx)
 	detright := (ax - cx) * (by - cy)
@@ -288,3 +319,10 @@ func Orient2D(ax, ay, bx, by, cx, cy float64) float64 {
 
 	return -orient2Dadapt(ax, ay, bx, by, cx, cy, detsum)
 }
+
+// Orient2DFast is like Orient2D but computes the determinant with plain
+// floating-point arithmetic, so its sign may be wrong for nearly collinear
+// points.
+func Orient2DFast(ax, ay, bx, by, cx, cy float64) float64 {
+	return (ay-cy)*(bx-cx) - (ax-cx)*(by-cy)
+}
@@ -31,27 +31,27 @@ func TestOrient2D(t *testing.T) {
 		}
 	}
 
-	// {
-	// 	r := 0.95
-	// 	q := 18.0
-	// 	p := 16.8
-	// 	w := math.Pow(2, -43)
-
-	// 	for i := 0; i < 128; i++ {
-	// 		for j := 0; j < 128; j++ {
-	// 			x := r + w*float64(i)/128.0
-	// 			y := r + w*float64(j)/128.0
+	{
+		// 128x128 near-collinear
+		r := 0.95
+		q := 18.0
+		p := 16.8
+		w := math.Pow(2, -43)
 
-	// 			o := concaveman.Orient2D(x, y, q, q, p, p)
-	// 			// var o2 = robustOrientation[3]([x, y], [q, q], [p, p])
+		for i := 0; i < 128; i++ {
+			for j := 0; j < 128; j++ {
+				x := r + w*float64(i)/128.0
+				y := r + w*float64(j)/128.0
 
-	// 			// if (Math.sign(o) !== Math.sign(o2)) {
-	// 			//     assert.fail(`${x},${y}, ${q},${q}, ${p},${p}: ${o} vs ${o2}`);
-	// 			// }
-	// 		}
-	// 	}
-	// 	// 512x512 near-collinear
-	// }
+				o := predicates.Orient2D(x, y, q, q, p, p)
+				// Orient2D has the opposite sign of the usual determinant
+				o2 := -exactSign([][]float64{{x, y}, {q, q}}, []float64{p, p}, false)
+				if sign(o) != o2 {
+					t.Fatalf("%v,%v, %v,%v, %v,%v: %v vs %d", x, y, q, q, p, p, o, o2)
+				}
+			}
+		}
+	}
 
 	{
 		f, _ := os.Open("testdata/orient2d.txt")
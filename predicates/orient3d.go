@@ -0,0 +1,76 @@
+package predicates
+
+import "math"
+
+const o3derrboundA = (7 + 56*epsilon) * epsilon
+
+// Orient3D returns a positive value if the point d lies below the plane
+// through a, b and c, below being the side from which a, b and c appear in
+// clockwise order, a negative value if it lies above, and zero if the four
+// points are coplanar. For a, b and c in a plane of constant z and d above
+// it, the result has the sign of Orient2D(a, b, c). The sign is exact; the
+// magnitude approximates six times the signed volume of the tetrahedron abcd.
+func Orient3D(ax, ay, az, bx, by, bz, cx, cy, cz, dx, dy, dz float64) float64 {
+	adx := ax - dx
+	bdx := bx - dx
+	cdx := cx - dx
+	ady := ay - dy
+	bdy := by - dy
+	cdy := cy - dy
+	adz := az - dz
+	bdz := bz - dz
+	cdz := cz - dz
+
+	bdxcdy := bdx * cdy
+	cdxbdy := cdx * bdy
+
+	cdxady := cdx * ady
+	adxcdy := adx * cdy
+
+	adxbdy := adx * bdy
+	bdxady := bdx * ady
+
+	det := adz*(bdxcdy-cdxbdy) +
+		bdz*(cdxady-adxcdy) +
+		cdz*(adxbdy-bdxady)
+
+	permanent := (math.Abs(bdxcdy)+math.Abs(cdxbdy))*math.Abs(adz) +
+		(math.Abs(cdxady)+math.Abs(adxcdy))*math.Abs(bdz) +
+		(math.Abs(adxbdy)+math.Abs(bdxady))*math.Abs(cdz)
+	errbound := o3derrboundA * permanent
+	if det > errbound || -det > errbound {
+		return det
+	}
+	return orient3dExact(ax, ay, az, bx, by, bz, cx, cy, cz, dx, dy, dz)
+}
+
+// Orient3DFast is like Orient3D but computes the determinant with plain
+// floating-point arithmetic, so its sign may be wrong for nearly coplanar
+// points.
+func Orient3DFast(ax, ay, az, bx, by, bz, cx, cy, cz, dx, dy, dz float64) float64 {
+	adx := ax - dx
+	bdx := bx - dx
+	cdx := cx - dx
+	ady := ay - dy
+	bdy := by - dy
+	cdy := cy - dy
+	adz := az - dz
+	bdz := bz - dz
+	cdz := cz - dz
+
+	return adx*(bdy*cdz-bdz*cdy) +
+		bdx*(cdy*adz-cdz*ady) +
+		cdx*(ady*bdz-adz*bdy)
+}
+
+func orient3dExact(ax, ay, az, bx, by, bz, cx, cy, cz, dx, dy, dz float64) float64 {
+	adx, ady, adz := diff(ax, dx), diff(ay, dy), diff(az, dz)
+	bdx, bdy, bdz := diff(bx, dx), diff(by, dy), diff(bz, dz)
+	cdx, cdy, cdz := diff(cx, dx), diff(cy, dy), diff(cz, dz)
+
+	bc := bdx.mul(cdy).sub(cdx.mul(bdy))
+	ca := cdx.mul(ady).sub(adx.mul(cdy))
+	ab := adx.mul(bdy).sub(bdx.mul(ady))
+
+	return adz.mul(bc).add(bdz.mul(ca)).add(cdz.mul(ab)).value()
+}
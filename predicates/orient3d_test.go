@@ -0,0 +1,73 @@
+package predicates_test
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+
+	"github.com/wsw0108/concaveman-go/predicates"
+)
+
+func TestOrient3D(t *testing.T) {
+	// (0,0,0), (1,0,0), (0,1,0) are counterclockwise seen from above
+	if v := predicates.Orient3D(0, 0, 0, 1, 0, 0, 0, 1, 0, 0, 0, -1); v <= 0 {
+		t.Error("below")
+	}
+	if v := predicates.Orient3D(0, 0, 0, 1, 0, 0, 0, 1, 0, 0, 0, 1); v >= 0 {
+		t.Error("above")
+	}
+	if v := predicates.Orient3D(0, 0, 0, 1, 0, 0, 0, 1, 0, 0.3, 0.3, 0); v != 0 {
+		t.Error("coplanar")
+	}
+
+	// 1000 hard fixtures
+	for _, f := range readFixtures(t, "testdata/orient3d.txt", 12) {
+		c := f.coords
+		got := sign(predicates.Orient3D(c[0], c[1], c[2], c[3], c[4], c[5], c[6], c[7], c[8], c[9], c[10], c[11]))
+		if got != f.sign {
+			t.Errorf("%s: got %d", f.line, got)
+		}
+	}
+}
+
+func TestOrient3DNearCoplanar(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	a := []float64{0.1, 0.2, 0.3}
+	b := []float64{17.5, -3.25, 9}
+	c := []float64{-4, 12.125, 2.5}
+	for i := 0; i < 1000; i++ {
+		s, u := r.Float64(), r.Float64()
+		d := make([]float64, 3)
+		for k := range d {
+			d[k] = a[k] + s*(b[k]-a[k]) + u*(c[k]-a[k])
+		}
+		d[2] += float64(r.Intn(3)-1) * math.Pow(2, -50)
+		want := exactSign([][]float64{a, b, c}, d, false)
+		got := sign(predicates.Orient3D(a[0], a[1], a[2], b[0], b[1], b[2], c[0], c[1], c[2], d[0], d[1], d[2]))
+		if got != want {
+			t.Fatalf("%v: got %d, want %d", d, got, want)
+		}
+	}
+}
+
+func TestOrient3DFast(t *testing.T) {
+	if v := predicates.Orient3DFast(0, 0, 0, 1, 0, 0, 0, 1, 0, 0, 0, -1); v <= 0 {
+		t.Error("below")
+	}
+}
+
+func TestSignConventions(t *testing.T) {
+	// a, b and c counterclockwise with the y axis up
+	if o := predicates.Orient2D(0, 0, 1, 0, 0, 1); o >= 0 {
+		t.Errorf("Orient2D = %v, want negative", o)
+	}
+	if o := predicates.Orient3D(0, 0, 0, 1, 0, 0, 0, 1, 0, 0.2, 0.2, 1); o >= 0 {
+		t.Errorf("Orient3D above = %v, want negative", o)
+	}
+	if o := predicates.InCircle(0, 0, 1, 0, 0, 1, 0.2, 0.2); o <= 0 {
+		t.Errorf("InCircle inside = %v, want positive", o)
+	}
+	if o := predicates.InSphere(0, 0, 0, 1, 0, 0, 0, 1, 0, 0, 0, -1, 0.1, 0.1, -0.1); o <= 0 {
+		t.Errorf("InSphere inside = %v, want positive", o)
+	}
+}
@@ -0,0 +1,127 @@
+package predicates
+
+import (
+	"fmt"
+	"math"
+)
+
+// SegmentRelation classifies how two segments meet.
+type SegmentRelation int
+
+const (
+	// Disjoint segments have no point in common.
+	Disjoint SegmentRelation = iota
+	// Crossing segments meet at a single point inside both of them.
+	Crossing
+	// Touching segments meet at a single point that is an endpoint of at
+	// least one of them.
+	Touching
+	// Overlapping segments are collinear and share a segment of positive
+	// length.
+	Overlapping
+)
+
+func (r SegmentRelation) String() string {
+	switch r {
+	case Disjoint:
+		return "disjoint"
+	case Crossing:
+		return "crossing"
+	case Touching:
+		return "touching"
+	case Overlapping:
+		return "overlapping"
+	}
+	return fmt.Sprintf("SegmentRelation(%d)", int(r))
+}
+
+// Intersection describes where two segments meet.
+type Intersection struct {
+	Relation SegmentRelation
+	// P is the common point of crossing or touching segments, and the start
+	// of the common part of overlapping segments. Q is the end of that common
+	// part, ordered along the first segment, and equals P otherwise. Both are
+	// zero for disjoint segments.
+	P, Q [2]float64
+}
+
+// IntersectSegments classifies the segments (a,b) and (c,d) and computes
+// where they meet. The classification is exact, and so are the points of
+// touching and overlapping segments, which are endpoints. The crossing point
+// of crossing segments is rounded, off by a few units in the last place of the
+// coordinates of a and b, but always inside the bounding boxes of both
+// segments. Segments may be degenerate, with equal endpoints.
+func IntersectSegments(ax, ay, bx, by, cx, cy, dx, dy float64) Intersection {
+	d1 := Orient2D(cx, cy, dx, dy, ax, ay)
+	d2 := Orient2D(cx, cy, dx, dy, bx, by)
+	d3 := Orient2D(ax, ay, bx, by, cx, cy)
+	d4 := Orient2D(ax, ay, bx, by, dx, dy)
+
+	if d1 == 0 && d2 == 0 && d3 == 0 && d4 == 0 {
+		return collinearIntersection([2]float64{ax, ay}, [2]float64{bx, by}, [2]float64{cx, cy}, [2]float64{dx, dy})
+	}
+	if (d1 > 0 && d2 > 0) || (d1 < 0 && d2 < 0) || (d3 > 0 && d4 > 0) || (d3 < 0 && d4 < 0) {
+		return Intersection{}
+	}
+
+	// an endpoint on the line through the other segment lies on that segment,
+	// since the other segment's ends are on both sides of its own line
+	var p [2]float64
+	switch {
+	case d1 == 0:
+		p = [2]float64{ax, ay}
+	case d2 == 0:
+		p = [2]float64{bx, by}
+	case d3 == 0:
+		p = [2]float64{cx, cy}
+	case d4 == 0:
+		p = [2]float64{dx, dy}
+	default:
+		t := d1 / (d1 - d2)
+		p = [2]float64{ax + t*(bx-ax), ay + t*(by-ay)}
+		// keep the rounded point inside both bounding boxes
+		p[0] = clamp(p[0], math.Max(math.Min(ax, bx), math.Min(cx, dx)), math.Min(math.Max(ax, bx), math.Max(cx, dx)))
+		p[1] = clamp(p[1], math.Max(math.Min(ay, by), math.Min(cy, dy)), math.Min(math.Max(ay, by), math.Max(cy, dy)))
+		return Intersection{Relation: Crossing, P: p, Q: p}
+	}
+	return Intersection{Relation: Touching, P: p, Q: p}
+}
+
+// collinearIntersection intersects segments on a common line, ordering their
+// endpoints along it
+func collinearIntersection(a, b, c, d [2]float64) Intersection {
+	reversed := less(b, a)
+	if reversed {
+		a, b = b, a
+	}
+	if less(d, c) {
+		c, d = d, c
+	}
+	start, end := a, b
+	if less(start, c) {
+		start = c
+	}
+	if less(d, end) {
+		end = d
+	}
+	switch {
+	case less(end, start):
+		return Intersection{}
+	case start == end:
+		return Intersection{Relation: Touching, P: start, Q: start}
+	}
+	if reversed {
+		start, end = end, start
+	}
+	return Intersection{Relation: Overlapping, P: start, Q: end}
+}
+
+// less orders points lexicographically, which orders collinear points along
+// their line
+func less(p, q [2]float64) bool {
+	return p[0] < q[0] || (p[0] == q[0] && p[1] < q[1])
+}
+
+func clamp(v, min, max float64) float64 {
+	return math.Max(min, math.Min(max, v))
+}
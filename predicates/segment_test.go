@@ -0,0 +1,86 @@
+package predicates_test
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+
+	"github.com/wsw0108/concaveman-go/predicates"
+)
+
+func TestIntersectSegments(t *testing.T) {
+	tests := []struct {
+		name       string
+		a, b, c, d [2]float64
+		want       predicates.Intersection
+	}{
+		{"crossing", [2]float64{0, 0}, [2]float64{2, 2}, [2]float64{0, 2}, [2]float64{2, 0},
+			predicates.Intersection{predicates.Crossing, [2]float64{1, 1}, [2]float64{1, 1}}},
+		{"disjoint", [2]float64{0, 0}, [2]float64{1, 1}, [2]float64{0, 2}, [2]float64{0.9, 1.1},
+			predicates.Intersection{}},
+		{"parallel", [2]float64{0, 0}, [2]float64{1, 1}, [2]float64{0, 1}, [2]float64{1, 2},
+			predicates.Intersection{}},
+		{"shared endpoint", [2]float64{0, 0}, [2]float64{1, 1}, [2]float64{1, 1}, [2]float64{2, 0},
+			predicates.Intersection{predicates.Touching, [2]float64{1, 1}, [2]float64{1, 1}}},
+		{"endpoint inside", [2]float64{0, 0}, [2]float64{2, 0}, [2]float64{1, 0}, [2]float64{1, 5},
+			predicates.Intersection{predicates.Touching, [2]float64{1, 0}, [2]float64{1, 0}}},
+		{"collinear disjoint", [2]float64{0, 0}, [2]float64{1, 1}, [2]float64{2, 2}, [2]float64{3, 3},
+			predicates.Intersection{}},
+		{"collinear touching", [2]float64{0, 0}, [2]float64{1, 1}, [2]float64{2, 2}, [2]float64{1, 1},
+			predicates.Intersection{predicates.Touching, [2]float64{1, 1}, [2]float64{1, 1}}},
+		{"overlapping", [2]float64{0, 0}, [2]float64{2, 2}, [2]float64{3, 3}, [2]float64{1, 1},
+			predicates.Intersection{predicates.Overlapping, [2]float64{1, 1}, [2]float64{2, 2}}},
+		{"overlapping reversed", [2]float64{2, 2}, [2]float64{0, 0}, [2]float64{3, 3}, [2]float64{1, 1},
+			predicates.Intersection{predicates.Overlapping, [2]float64{2, 2}, [2]float64{1, 1}}},
+		{"vertical overlap", [2]float64{0, 0}, [2]float64{0, 4}, [2]float64{0, 1}, [2]float64{0, 2},
+			predicates.Intersection{predicates.Overlapping, [2]float64{0, 1}, [2]float64{0, 2}}},
+		{"degenerate on segment", [2]float64{1, 1}, [2]float64{1, 1}, [2]float64{0, 0}, [2]float64{2, 2},
+			predicates.Intersection{predicates.Touching, [2]float64{1, 1}, [2]float64{1, 1}}},
+		{"degenerate off segment", [2]float64{1, 1}, [2]float64{1, 1}, [2]float64{0, 0}, [2]float64{2, 0},
+			predicates.Intersection{}},
+		{"equal points", [2]float64{1, 1}, [2]float64{1, 1}, [2]float64{1, 1}, [2]float64{1, 1},
+			predicates.Intersection{predicates.Touching, [2]float64{1, 1}, [2]float64{1, 1}}},
+	}
+	for _, tt := range tests {
+		got := predicates.IntersectSegments(tt.a[0], tt.a[1], tt.b[0], tt.b[1], tt.c[0], tt.c[1], tt.d[0], tt.d[1])
+		if got != tt.want {
+			t.Errorf("%s: got %+v, want %+v", tt.name, got, tt.want)
+		}
+		// the relation doesn't depend on the order of the segments
+		swapped := predicates.IntersectSegments(tt.c[0], tt.c[1], tt.d[0], tt.d[1], tt.a[0], tt.a[1], tt.b[0], tt.b[1])
+		if swapped.Relation != tt.want.Relation {
+			t.Errorf("%s: swapped got %v, want %v", tt.name, swapped.Relation, tt.want.Relation)
+		}
+	}
+}
+
+func TestIntersectSegmentsNearlyParallel(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	for i := 0; i < 1000; i++ {
+		// a long segment crossed at a shallow angle
+		a := [2]float64{r.Float64(), r.Float64()}
+		b := [2]float64{a[0] + 1e6*r.Float64(), a[1] + 1e6*r.Float64()}
+		s := r.Float64()
+		m := [2]float64{a[0] + s*(b[0]-a[0]), a[1] + s*(b[1]-a[1])}
+		c := [2]float64{m[0] - 1e3, m[1] - 1e3 + 1e-6}
+		d := [2]float64{m[0] + 1e3, m[1] + 1e3 - 1e-6}
+
+		got := predicates.IntersectSegments(a[0], a[1], b[0], b[1], c[0], c[1], d[0], d[1])
+		if got.Relation == predicates.Disjoint {
+			continue
+		}
+		p := got.P
+		for _, seg := range [][2][2]float64{{a, b}, {c, d}} {
+			if p[0] < math.Min(seg[0][0], seg[1][0]) || p[0] > math.Max(seg[0][0], seg[1][0]) ||
+				p[1] < math.Min(seg[0][1], seg[1][1]) || p[1] > math.Max(seg[0][1], seg[1][1]) {
+				t.Fatalf("%v is outside the bounding box of %v", p, seg)
+			}
+		}
+	}
+}
+
+func TestSegmentRelationString(t *testing.T) {
+	if s := predicates.Overlapping.String(); s != "overlapping" {
+		t.Errorf("got %q", s)
+	}
+}
@@ -0,0 +1,141 @@
+// Package proj implements a few map projections, so that hulls of
+// [longitude, latitude] points can be computed in meters without external
+// PROJ bindings. Angles are in degrees and planar coordinates in meters.
+package proj
+
+import (
+	"math"
+)
+
+// Projection maps geographic coordinates to the plane and back.
+type Projection interface {
+	Forward(lon, lat float64) (x, y float64)
+	Inverse(x, y float64) (lon, lat float64)
+}
+
+const (
+	// the semi-major axis and flattening of the WGS84 ellipsoid
+	wgs84A = 6378137
+	wgs84F = 1 / 298.257223563
+
+	// EarthRadius is the mean radius of the Earth in meters, used by the
+	// spherical projections.
+	EarthRadius = 6371008.8
+
+	// MaxMercatorLat is the latitude at which Web Mercator becomes square.
+	MaxMercatorLat = 85.051128779806604
+
+	deg2rad = math.Pi / 180
+)
+
+var (
+	_ Projection = WebMercator{}
+	_ Projection = UTM{}
+	_ Projection = AzimuthalEquidistant{}
+	_ Projection = LambertAzimuthal{}
+)
+
+// WebMercator is the spherical Mercator projection of web maps (EPSG:3857).
+// Latitudes are clamped to ±MaxMercatorLat.
+type WebMercator struct{}
+
+func (WebMercator) Forward(lon, lat float64) (x, y float64) {
+	lat = math.Max(-MaxMercatorLat, math.Min(MaxMercatorLat, lat))
+	x = wgs84A * lon * deg2rad
+	y = wgs84A * math.Log(math.Tan(math.Pi/4+lat*deg2rad/2))
+	return x, y
+}
+
+func (WebMercator) Inverse(x, y float64) (lon, lat float64) {
+	lon = x / wgs84A / deg2rad
+	lat = (2*math.Atan(math.Exp(y/wgs84A)) - math.Pi/2) / deg2rad
+	return lon, lat
+}
+
+// AzimuthalEquidistant is the spherical azimuthal equidistant projection
+// centered on (Lon, Lat). Distances and directions from the center are true.
+type AzimuthalEquidistant struct {
+	Lon, Lat float64
+}
+
+func (p AzimuthalEquidistant) Forward(lon, lat float64) (x, y float64) {
+	return azimuthalForward(p.Lon, p.Lat, lon, lat, func(c float64) float64 {
+		if c == 0 {
+			return 1
+		}
+		return c / math.Sin(c)
+	})
+}
+
+func (p AzimuthalEquidistant) Inverse(x, y float64) (lon, lat float64) {
+	return azimuthalInverse(p.Lon, p.Lat, x, y, math.Hypot(x, y)/EarthRadius)
+}
+
+// LambertAzimuthal is the spherical Lambert azimuthal equal-area projection
+// centered on (Lon, Lat). Areas are true.
+type LambertAzimuthal struct {
+	Lon, Lat float64
+}
+
+func (p LambertAzimuthal) Forward(lon, lat float64) (x, y float64) {
+	return azimuthalForward(p.Lon, p.Lat, lon, lat, func(c float64) float64 {
+		return math.Sqrt(2 / (1 + math.Cos(c)))
+	})
+}
+
+func (p LambertAzimuthal) Inverse(x, y float64) (lon, lat float64) {
+	rho := math.Hypot(x, y)
+	return azimuthalInverse(p.Lon, p.Lat, x, y, 2*math.Asin(math.Min(1, rho/(2*EarthRadius))))
+}
+
+// azimuthalForward projects with the radial scale k of the angular distance c
+// from the center (Snyder, Map Projections: A Working Manual, §24-25)
+func azimuthalForward(lon0, lat0, lon, lat float64, k func(c float64) float64) (x, y float64) {
+	sinLat0, cosLat0 := math.Sincos(lat0 * deg2rad)
+	sinLat, cosLat := math.Sincos(lat * deg2rad)
+	sinLon, cosLon := math.Sincos((lon - lon0) * deg2rad)
+	cosC := sinLat0*sinLat + cosLat0*cosLat*cosLon
+	c := math.Acos(math.Max(-1, math.Min(1, cosC)))
+	r := EarthRadius * k(c)
+	x = r * cosLat * sinLon
+	y = r * (cosLat0*sinLat - sinLat0*cosLat*cosLon)
+	return x, y
+}
+
+// azimuthalInverse unprojects a point at angular distance c from the center
+func azimuthalInverse(lon0, lat0, x, y, c float64) (lon, lat float64) {
+	rho := math.Hypot(x, y)
+	if rho == 0 {
+		return lon0, lat0
+	}
+	sinLat0, cosLat0 := math.Sincos(lat0 * deg2rad)
+	sinC, cosC := math.Sincos(c)
+	lat = math.Asin(math.Max(-1, math.Min(1, cosC*sinLat0+y*sinC*cosLat0/rho)))
+	lon = lon0*deg2rad + math.Atan2(x*sinC, rho*cosLat0*cosC-y*sinLat0*sinC)
+	return normalizeLon(lon / deg2rad), lat / deg2rad
+}
+
+// Center returns the geographic center of the points, the direction of the
+// mean of their unit vectors. It is well-defined across the antimeridian.
+func Center[P ~[2]float64](points []P) (lon, lat float64) {
+	var x, y, z float64
+	for _, p := range points {
+		sinLat, cosLat := math.Sincos(p[1] * deg2rad)
+		sinLon, cosLon := math.Sincos(p[0] * deg2rad)
+		x += cosLat * cosLon
+		y += cosLat * sinLon
+		z += sinLat
+	}
+	lon = math.Atan2(y, x) / deg2rad
+	lat = math.Atan2(z, math.Hypot(x, y)) / deg2rad
+	return lon, lat
+}
+
+// normalizeLon wraps a longitude into [-180, 180)
+func normalizeLon(lon float64) float64 {
+	lon = math.Mod(lon+180, 360)
+	if lon < 0 {
+		lon += 360
+	}
+	return lon - 180
+}
@@ -0,0 +1,107 @@
+package proj_test
+
+import (
+	"math"
+	"testing"
+
+	"github.com/wsw0108/concaveman-go/proj"
+)
+
+func TestRoundTrip(t *testing.T) {
+	tests := []struct {
+		name string
+		p    proj.Projection
+		lon  float64
+		lat  float64
+	}{
+		{"WebMercator", proj.WebMercator{}, -122.4, 37.8},
+		{"UTM", proj.UTM{Zone: 10}, -122.4, 37.8},
+		{"UTM south", proj.UTM{Zone: 56, South: true}, 151.2, -33.9},
+		{"UTM zone edge", proj.UTM{Zone: 33}, 18, 60},
+		{"AzimuthalEquidistant", proj.AzimuthalEquidistant{Lon: 179, Lat: -10}, -175, -20},
+		{"AzimuthalEquidistant far", proj.AzimuthalEquidistant{Lon: 0, Lat: 45}, 100, -30},
+		{"LambertAzimuthal", proj.LambertAzimuthal{Lon: 10, Lat: 50}, 20, 60},
+		{"LambertAzimuthal center", proj.LambertAzimuthal{Lon: 10, Lat: 50}, 10, 50},
+	}
+	for _, tt := range tests {
+		x, y := tt.p.Forward(tt.lon, tt.lat)
+		lon, lat := tt.p.Inverse(x, y)
+		if math.Abs(lon-tt.lon) > 1e-9 || math.Abs(lat-tt.lat) > 1e-9 {
+			t.Errorf("%s: got (%v, %v), want (%v, %v)", tt.name, lon, lat, tt.lon, tt.lat)
+		}
+	}
+}
+
+func TestForward(t *testing.T) {
+	tests := []struct {
+		name   string
+		p      proj.Projection
+		lon    float64
+		lat    float64
+		x, y   float64
+		within float64
+	}{
+		{"WebMercator", proj.WebMercator{}, 180, 0, 20037508.342789244, 0, 1e-6},
+		{"WebMercator clamped", proj.WebMercator{}, -180, 90, -20037508.342789244, 20037508.342789244, 1e-6},
+		// the meridian arc from the equator to 45° is 4984944.378m
+		{"UTM central meridian", proj.UTM{Zone: 31}, 3, 45, 500000, 0.9996 * 4984944.378, 1e-3},
+		{"UTM equator", proj.UTM{Zone: 31, South: true}, 3, 0, 500000, 10000000, 1e-6},
+		{"AzimuthalEquidistant", proj.AzimuthalEquidistant{Lon: 20, Lat: 0}, 20, 1, 0, proj.EarthRadius * math.Pi / 180, 1e-6},
+		{"LambertAzimuthal", proj.LambertAzimuthal{Lon: 20, Lat: 0}, 20, 0, 0, 0, 0},
+	}
+	for _, tt := range tests {
+		x, y := tt.p.Forward(tt.lon, tt.lat)
+		if math.Abs(x-tt.x) > tt.within || math.Abs(y-tt.y) > tt.within {
+			t.Errorf("%s: got (%v, %v), want (%v, %v)", tt.name, x, y, tt.x, tt.y)
+		}
+	}
+}
+
+func TestLambertAzimuthalArea(t *testing.T) {
+	// a 1° cell at the equator, far from the center
+	p := proj.LambertAzimuthal{Lon: 0, Lat: 40}
+	corners := [][2]float64{{50, 0}, {51, 0}, {51, 1}, {50, 1}, {50, 0}}
+	var area float64
+	for i := 0; i+1 < len(corners); i++ {
+		x0, y0 := p.Forward(corners[i][0], corners[i][1])
+		x1, y1 := p.Forward(corners[i+1][0], corners[i+1][1])
+		area += x0*y1 - x1*y0
+	}
+	area = math.Abs(area) / 2
+	// R²·Δλ·(sin φ1 - sin φ0)
+	want := proj.EarthRadius * proj.EarthRadius * math.Pi / 180 * math.Sin(math.Pi/180)
+	if math.Abs(area-want)/want > 1e-3 {
+		t.Errorf("TestLambertAzimuthalArea: got %v, want %v", area, want)
+	}
+}
+
+func TestUTMZone(t *testing.T) {
+	tests := []struct {
+		lon, lat float64
+		want     proj.UTM
+	}{
+		{-75, 40, proj.UTM{Zone: 18}},
+		{-0.5, -10, proj.UTM{Zone: 30, South: true}},
+		{180, 10, proj.UTM{Zone: 1}},
+		{179.9, 10, proj.UTM{Zone: 60}},
+		{5, 60, proj.UTM{Zone: 32}},
+		{5, 78, proj.UTM{Zone: 31}},
+		{10, 78, proj.UTM{Zone: 33}},
+		{40, 78, proj.UTM{Zone: 37}},
+	}
+	for _, tt := range tests {
+		if got := proj.UTMZone(tt.lon, tt.lat); got != tt.want {
+			t.Errorf("UTMZone(%v, %v) = %v, want %v", tt.lon, tt.lat, got, tt.want)
+		}
+	}
+}
+
+func TestCenter(t *testing.T) {
+	lon, lat := proj.Center([][2]float64{{179, 1}, {-179, -1}})
+	if math.Abs(math.Abs(lon)-180) > 1e-9 || math.Abs(lat) > 1e-9 {
+		t.Errorf("TestCenter: got (%v, %v)", lon, lat)
+	}
+	if got := proj.AutoUTM([][2]float64{{179, 1}, {178, 1}}); got != (proj.UTM{Zone: 60}) {
+		t.Errorf("TestCenter: AutoUTM = %v", got)
+	}
+}
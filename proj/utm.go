@@ -0,0 +1,115 @@
+package proj
+
+import (
+	"math"
+)
+
+// UTM is a zone of the Universal Transverse Mercator system on the WGS84
+// ellipsoid. Northings in the southern hemisphere are offset by 10000km.
+type UTM struct {
+	Zone  int // 1 to 60
+	South bool
+}
+
+const (
+	utmScale    = 0.9996
+	utmEasting  = 500000
+	utmNorthing = 10000000
+)
+
+// the coefficients of Krüger's series to the fourth order in the third
+// flattening n (Karney, Transverse Mercator with an accuracy of a few
+// nanometers, 2011)
+var (
+	utmN     = wgs84F / (2 - wgs84F)
+	utmRectA = wgs84A / (1 + utmN) * (1 + utmN*utmN/4 + utmN*utmN*utmN*utmN/64)
+	utmAlpha = [4]float64{
+		utmN/2 - 2*utmN*utmN/3 + 5*utmN*utmN*utmN/16 + 41*utmN*utmN*utmN*utmN/180,
+		13*utmN*utmN/48 - 3*utmN*utmN*utmN/5 + 557*utmN*utmN*utmN*utmN/1440,
+		61*utmN*utmN*utmN/240 - 103*utmN*utmN*utmN*utmN/140,
+		49561 * utmN * utmN * utmN * utmN / 161280,
+	}
+	utmBeta = [4]float64{
+		utmN/2 - 2*utmN*utmN/3 + 37*utmN*utmN*utmN/96 - utmN*utmN*utmN*utmN/360,
+		utmN*utmN/48 + utmN*utmN*utmN/15 - 437*utmN*utmN*utmN*utmN/1440,
+		17*utmN*utmN*utmN/480 - 37*utmN*utmN*utmN*utmN/840,
+		4397 * utmN * utmN * utmN * utmN / 161280,
+	}
+	utmDelta = [4]float64{
+		2*utmN - 2*utmN*utmN/3 - 2*utmN*utmN*utmN + 116*utmN*utmN*utmN*utmN/45,
+		7*utmN*utmN/3 - 8*utmN*utmN*utmN/5 - 227*utmN*utmN*utmN*utmN/45,
+		56*utmN*utmN*utmN/15 - 136*utmN*utmN*utmN*utmN/35,
+		4279 * utmN * utmN * utmN * utmN / 630,
+	}
+)
+
+// UTMZone returns the zone containing the given location, including the
+// exceptions around Norway and Svalbard.
+func UTMZone(lon, lat float64) UTM {
+	lon = normalizeLon(lon)
+	zone := int(math.Floor((lon+180)/6)) + 1
+	switch {
+	case lat >= 56 && lat < 64 && lon >= 3 && lon < 12:
+		zone = 32
+	case lat >= 72 && lat < 84 && lon >= 0 && lon < 42:
+		zone = 2*int(math.Floor((lon+3)/12)) + 31
+	}
+	return UTM{Zone: zone, South: lat < 0}
+}
+
+// AutoUTM returns the zone containing the center of the points.
+func AutoUTM[P ~[2]float64](points []P) UTM {
+	return UTMZone(Center(points))
+}
+
+// centralMeridian returns the longitude of the zone's central meridian
+func (u UTM) centralMeridian() float64 {
+	return float64(u.Zone)*6 - 183
+}
+
+func (u UTM) Forward(lon, lat float64) (x, y float64) {
+	n := utmN
+	phi := lat * deg2rad
+	lambda := normalizeLon(lon-u.centralMeridian()) * deg2rad
+
+	e := 2 * math.Sqrt(n) / (1 + n)
+	sinPhi := math.Sin(phi)
+	t := math.Sinh(math.Atanh(sinPhi) - e*math.Atanh(e*sinPhi))
+	xi := math.Atan2(t, math.Cos(lambda))
+	eta := math.Atanh(math.Sin(lambda) / math.Sqrt(1+t*t))
+
+	x, y = eta, xi
+	for j, a := range utmAlpha {
+		k := float64(2 * (j + 1))
+		x += a * math.Cos(k*xi) * math.Sinh(k*eta)
+		y += a * math.Sin(k*xi) * math.Cosh(k*eta)
+	}
+	x = utmEasting + utmScale*utmRectA*x
+	y = utmScale * utmRectA * y
+	if u.South {
+		y += utmNorthing
+	}
+	return x, y
+}
+
+func (u UTM) Inverse(x, y float64) (lon, lat float64) {
+	if u.South {
+		y -= utmNorthing
+	}
+	xi := y / (utmScale * utmRectA)
+	eta := (x - utmEasting) / (utmScale * utmRectA)
+
+	xi1, eta1 := xi, eta
+	for j, b := range utmBeta {
+		k := float64(2 * (j + 1))
+		xi1 -= b * math.Sin(k*xi) * math.Cosh(k*eta)
+		eta1 -= b * math.Cos(k*xi) * math.Sinh(k*eta)
+	}
+	chi := math.Asin(math.Sin(xi1) / math.Cosh(eta1))
+	phi := chi
+	for j, d := range utmDelta {
+		phi += d * math.Sin(float64(2*(j+1))*chi)
+	}
+	lambda := math.Atan2(math.Sinh(eta1), math.Cos(xi1))
+	return normalizeLon(u.centralMeridian() + lambda/deg2rad), phi / deg2rad
+}
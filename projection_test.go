@@ -0,0 +1,69 @@
+package concaveman_test
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"github.com/wsw0108/concaveman-go"
+	"github.com/wsw0108/concaveman-go/proj"
+)
+
+func TestProjection(t *testing.T) {
+	points := uShape()
+	lon, lat := proj.Center(points)
+	projections := []struct {
+		name string
+		p    concaveman.Projection
+	}{
+		{"UTM", proj.AutoUTM(points)},
+		{"AzimuthalEquidistant", proj.AzimuthalEquidistant{Lon: lon, Lat: lat}},
+		{"LambertAzimuthal", proj.LambertAzimuthal{Lon: lon, Lat: lat}},
+	}
+	for _, tt := range projections {
+		name := "TestProjection " + tt.name
+		opt := concaveman.Options{Concavity: 1, Projection: tt.p}
+		testLengthThreshold(t, name, opt)
+
+		// the hull is made of input points, not projected ones
+		known := make(map[concaveman.Point]bool, len(points))
+		for _, p := range points {
+			known[p] = true
+		}
+		for _, p := range concaveman.Concaveman(points, opt) {
+			if !known[p] {
+				t.Errorf("%s: unknown hull point %v", name, p)
+				break
+			}
+		}
+	}
+}
+
+func TestProjectionHull(t *testing.T) {
+	points := uShape()
+	opt := concaveman.Options{Concavity: 1, Projection: proj.AutoUTM(points)}
+	h, err := concaveman.NewHullOptions(points, opt)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := concaveman.Concaveman(points, opt)
+	got, _ := h.RefineContext(context.Background(), opt)
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("TestProjectionHull: got %v, want %v", got, want)
+	}
+
+	// points are given and returned in input coordinates
+	p := concaveman.Point{0.5, 69.9}
+	if err := h.AddPoints([]concaveman.Point{p}); err != nil {
+		t.Fatal(err)
+	}
+	if !containsPoint(h.Points(), p) {
+		t.Errorf("TestProjectionHull: added point missing from %v", h.Points())
+	}
+	if err := h.RemovePoints([]concaveman.Point{p}); err != nil {
+		t.Fatal(err)
+	}
+	if containsPoint(h.Points(), p) {
+		t.Errorf("TestProjectionHull: removed point still in %v", h.Points())
+	}
+}
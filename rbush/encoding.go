@@ -0,0 +1,351 @@
+package rbush
+
+import (
+	"bufio"
+	"bytes"
+	"encoding"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+)
+
+// ItemCodec writes and reads the items of a tree for Encode and Decode.
+type ItemCodec interface {
+	EncodeItem(w io.Writer, item Item) error
+	DecodeItem(r io.Reader) (Item, error)
+}
+
+// ErrFormat is returned when decoding data that does not describe a valid
+// tree.
+var ErrFormat = errors.New("rbush: invalid tree encoding")
+
+const (
+	binaryMagic   = "RBSH"
+	binaryVersion = 1
+	// the largest node size Decode accepts, which keeps a corrupt header from
+	// describing huge nodes
+	maxDecodeEntries = 1 << 16
+	// the encoded size of a nodeHeader
+	nodeHeaderSize = 40
+)
+
+// Encode writes the tree structure, so that Decode can restore it without
+// rebuilding, with the items written by codec. Nodes are written depth-first
+// as their bounding box, height and number of children, in little-endian
+// order.
+func (tr *RBush) Encode(w io.Writer, codec ItemCodec) error {
+	bw := bufio.NewWriter(w)
+	bw.WriteString(binaryMagic)
+	bw.WriteByte(binaryVersion)
+	if err := binary.Write(bw, binary.LittleEndian, uint32(tr.maxEntries)); err != nil {
+		return err
+	}
+	if err := encodeNode(bw, tr.Data, codec); err != nil {
+		return err
+	}
+	return bw.Flush()
+}
+
+type nodeHeader struct {
+	Min, Max [2]float64
+	Height   uint32
+	Len      uint32
+}
+
+func encodeNode(w io.Writer, node *TreeNode, codec ItemCodec) error {
+	header := nodeHeader{node.Min, node.Max, uint32(node.height), uint32(len(node.Children))}
+	if err := binary.Write(w, binary.LittleEndian, &header); err != nil {
+		return err
+	}
+	for _, child := range node.Children {
+		var err error
+		if node.Leaf {
+			err = codec.EncodeItem(w, child.(Item))
+		} else {
+			err = encodeNode(w, child.(*TreeNode), codec)
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Decode reads a tree written by Encode, with the items read by codec. It
+// reads from r in small pieces, so r should be buffered. Memory is allocated
+// as the nodes are read, and node sizes are checked against the remaining
+// input when r has a Len method like bytes.Reader, so corrupt input cannot
+// cause large allocations.
+func Decode(r io.Reader, codec ItemCodec) (*RBush, error) {
+	var magic [len(binaryMagic) + 1]byte
+	if _, err := io.ReadFull(r, magic[:]); err != nil {
+		return nil, err
+	}
+	if string(magic[:len(binaryMagic)]) != binaryMagic {
+		return nil, ErrFormat
+	}
+	if magic[len(binaryMagic)] != binaryVersion {
+		return nil, fmt.Errorf("rbush: unsupported encoding version %d", magic[len(binaryMagic)])
+	}
+	var maxEntries uint32
+	if err := binary.Read(r, binary.LittleEndian, &maxEntries); err != nil {
+		return nil, err
+	}
+	if maxEntries < 4 || maxEntries > maxDecodeEntries {
+		return nil, ErrFormat
+	}
+	tr := New(int(maxEntries))
+	root, err := decodeNode(r, codec, tr.maxEntries, 0)
+	if err != nil {
+		return nil, err
+	}
+	tr.Data = root
+	return tr, nil
+}
+
+// decodeNode reads a node of the given height, or of any height for the root
+func decodeNode(r io.Reader, codec ItemCodec, maxEntries, height int) (*TreeNode, error) {
+	var header nodeHeader
+	if err := binary.Read(r, binary.LittleEndian, &header); err != nil {
+		return nil, noEOF(err)
+	}
+	if header.Height < 1 || header.Height > 64 || (height > 0 && int(header.Height) != height) ||
+		int(header.Len) > maxEntries || (header.Height > 1 && header.Len == 0) {
+		return nil, ErrFormat
+	}
+	if lr, ok := r.(interface{ Len() int }); ok && header.Height > 1 && int(header.Len)*nodeHeaderSize > lr.Len() {
+		// not enough input left for the headers of the children
+		return nil, io.ErrUnexpectedEOF
+	}
+	node := createNode(nil)
+	node.Min, node.Max = header.Min, header.Max
+	node.height = int(header.Height)
+	node.Leaf = node.height == 1
+	for i := 0; i < int(header.Len); i++ {
+		if node.Leaf {
+			item, err := codec.DecodeItem(r)
+			if err != nil {
+				return nil, noEOF(err)
+			}
+			if item == nil {
+				return nil, ErrFormat
+			}
+			node.Children = append(node.Children, item)
+		} else {
+			child, err := decodeNode(r, codec, maxEntries, node.height-1)
+			if err != nil {
+				return nil, err
+			}
+			node.Children = append(node.Children, child)
+		}
+	}
+	return node, nil
+}
+
+// Binary pairs a tree with the codec of its items, to implement the standard
+// io.WriterTo, io.ReaderFrom, encoding.BinaryMarshaler and
+// encoding.BinaryUnmarshaler interfaces with Encode and Decode.
+type Binary struct {
+	Tree  *RBush
+	Codec ItemCodec
+}
+
+var (
+	_ io.WriterTo                = (*Binary)(nil)
+	_ io.ReaderFrom              = (*Binary)(nil)
+	_ encoding.BinaryMarshaler   = (*Binary)(nil)
+	_ encoding.BinaryUnmarshaler = (*Binary)(nil)
+)
+
+// WriteTo encodes the tree to w.
+func (b *Binary) WriteTo(w io.Writer) (int64, error) {
+	cw := &countingWriter{w: w}
+	err := b.Tree.Encode(cw, b.Codec)
+	return cw.n, err
+}
+
+// ReadFrom decodes a tree from r, which must hold nothing else, and replaces
+// Tree with it.
+func (b *Binary) ReadFrom(r io.Reader) (int64, error) {
+	cr := &countingReader{r: r}
+	br := bufio.NewReader(cr)
+	tr, err := Decode(br, b.Codec)
+	if err == nil {
+		if _, err = br.ReadByte(); err == io.EOF {
+			b.Tree, err = tr, nil
+		} else if err == nil {
+			err = ErrFormat
+		}
+	}
+	return cr.n, err
+}
+
+// MarshalBinary returns the encoding of the tree.
+func (b *Binary) MarshalBinary() ([]byte, error) {
+	var buf bytes.Buffer
+	if err := b.Tree.Encode(&buf, b.Codec); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// UnmarshalBinary decodes a tree from data, which must hold nothing else,
+// and replaces Tree with it.
+func (b *Binary) UnmarshalBinary(data []byte) error {
+	r := bytes.NewReader(data)
+	tr, err := Decode(r, b.Codec)
+	if err != nil {
+		return err
+	}
+	if r.Len() > 0 {
+		return ErrFormat
+	}
+	b.Tree = tr
+	return nil
+}
+
+type countingWriter struct {
+	w io.Writer
+	n int64
+}
+
+func (cw *countingWriter) Write(p []byte) (int, error) {
+	n, err := cw.w.Write(p)
+	cw.n += int64(n)
+	return n, err
+}
+
+type countingReader struct {
+	r io.Reader
+	n int64
+}
+
+func (cr *countingReader) Read(p []byte) (int, error) {
+	n, err := cr.r.Read(p)
+	cr.n += int64(n)
+	return n, err
+}
+
+// noEOF turns an end of input inside a tree into an unexpected one
+func noEOF(err error) error {
+	if err == io.EOF {
+		return io.ErrUnexpectedEOF
+	}
+	return err
+}
+
+// jsonNode is a node in the format of toJSON in the JavaScript rbush, where
+// leaf children are the items themselves. Infinite bounds of an empty tree
+// are written as null, as JSON.stringify does.
+type jsonNode struct {
+	Children []json.RawMessage `json:"children"`
+	Height   int               `json:"height"`
+	Leaf     bool              `json:"leaf"`
+	MinX     *float64          `json:"minX"`
+	MinY     *float64          `json:"minY"`
+	MaxX     *float64          `json:"maxX"`
+	MaxY     *float64          `json:"maxY"`
+}
+
+// ToJSON returns the tree in the format of toJSON in the JavaScript rbush.
+// Items are encoded with encoding/json.
+func (tr *RBush) ToJSON() ([]byte, error) {
+	return json.Marshal(toJSONNode(tr.Data))
+}
+
+// jsonOutNode is a jsonNode being written
+type jsonOutNode struct {
+	Children []interface{} `json:"children"`
+	Height   int           `json:"height"`
+	Leaf     bool          `json:"leaf"`
+	MinX     *float64      `json:"minX"`
+	MinY     *float64      `json:"minY"`
+	MaxX     *float64      `json:"maxX"`
+	MaxY     *float64      `json:"maxY"`
+}
+
+func toJSONNode(node *TreeNode) *jsonOutNode {
+	children := make([]interface{}, len(node.Children))
+	for i, child := range node.Children {
+		if node.Leaf {
+			children[i] = child
+		} else {
+			children[i] = toJSONNode(child.(*TreeNode))
+		}
+	}
+	return &jsonOutNode{
+		Children: children,
+		Height:   node.height,
+		Leaf:     node.Leaf,
+		MinX:     finite(node.Min[0]),
+		MinY:     finite(node.Min[1]),
+		MaxX:     finite(node.Max[0]),
+		MaxY:     finite(node.Max[1]),
+	}
+}
+
+func finite(x float64) *float64 {
+	if math.IsInf(x, 0) || math.IsNaN(x) {
+		return nil
+	}
+	return &x
+}
+
+// FromJSON replaces the contents of the tree with a tree in the format of
+// toJSON in the JavaScript rbush, such as one written by ToJSON, decoding each
+// item with decode. The tree keeps its own node size.
+func (tr *RBush) FromJSON(data []byte, decode func(raw json.RawMessage) (Item, error)) error {
+	var root jsonNode
+	if err := json.Unmarshal(data, &root); err != nil {
+		return err
+	}
+	node, err := fromJSONNode(&root, decode, 0)
+	if err != nil {
+		return err
+	}
+	tr.Data = node
+	return nil
+}
+
+func fromJSONNode(jn *jsonNode, decode func(raw json.RawMessage) (Item, error), height int) (*TreeNode, error) {
+	if jn.Height < 1 || (height > 0 && jn.Height != height) || jn.Leaf != (jn.Height == 1) ||
+		(jn.Height > 1 && len(jn.Children) == 0) {
+		return nil, ErrFormat
+	}
+	node := createNode(make([]interface{}, 0, len(jn.Children)))
+	node.height = jn.Height
+	node.Leaf = jn.Leaf
+	for _, raw := range jn.Children {
+		if node.Leaf {
+			item, err := decode(raw)
+			if err != nil {
+				return nil, err
+			}
+			if item == nil {
+				return nil, ErrFormat
+			}
+			node.Children = append(node.Children, item)
+		} else {
+			var child jsonNode
+			if err := json.Unmarshal(raw, &child); err != nil {
+				return nil, err
+			}
+			c, err := fromJSONNode(&child, decode, node.height-1)
+			if err != nil {
+				return nil, err
+			}
+			node.Children = append(node.Children, c)
+		}
+	}
+	if jn.MinX == nil || jn.MinY == nil || jn.MaxX == nil || jn.MaxY == nil {
+		// the empty root, or bounds that we can restore
+		calcBBox(node)
+	} else {
+		node.Min = [2]float64{*jn.MinX, *jn.MinY}
+		node.Max = [2]float64{*jn.MaxX, *jn.MaxY}
+	}
+	return node, nil
+}
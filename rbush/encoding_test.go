@@ -0,0 +1,211 @@
+package rbush_test
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"io"
+	"sort"
+	"testing"
+
+	"github.com/wsw0108/concaveman-go/internal/testutil"
+	"github.com/wsw0108/concaveman-go/rbush"
+)
+
+type pointCodec struct{}
+
+func (pointCodec) EncodeItem(w io.Writer, item rbush.Item) error {
+	return binary.Write(w, binary.LittleEndian, item.(point))
+}
+
+func (pointCodec) DecodeItem(r io.Reader) (rbush.Item, error) {
+	var p point
+	err := binary.Read(r, binary.LittleEndian, &p)
+	return p, err
+}
+
+func decodePoint(raw json.RawMessage) (rbush.Item, error) {
+	var p point
+	err := json.Unmarshal(raw, &p)
+	return p, err
+}
+
+// sortedPoints returns the points in the tree within the box (min, max)
+func sortedPoints(tree *rbush.RBush, min, max [2]float64) []point {
+	var points []point
+	tree.Search(testutil.Box{Min: min, Max: max}, func(item rbush.Item) bool {
+		points = append(points, item.(point))
+		return true
+	})
+	sort.Slice(points, func(i, j int) bool {
+		return points[i][0] < points[j][0] || points[i][0] == points[j][0] && points[i][1] < points[j][1]
+	})
+	return points
+}
+
+func sameTree(t *testing.T, name string, got, want *rbush.RBush) {
+	t.Helper()
+	for _, q := range [][2][2]float64{{{0, 0}, {100, 100}}, {{10, 20}, {30, 25}}, {{50, 50}, {50.5, 90}}} {
+		g, w := sortedPoints(got, q[0], q[1]), sortedPoints(want, q[0], q[1])
+		if len(g) != len(w) {
+			t.Errorf("%s: found %d points in %v, want %d", name, len(g), q, len(w))
+			continue
+		}
+		for i := range g {
+			if g[i] != w[i] {
+				t.Errorf("%s: point %d is %v, want %v", name, i, g[i], w[i])
+				break
+			}
+		}
+	}
+	if got.Data.Min != want.Data.Min || got.Data.Max != want.Data.Max {
+		t.Errorf("%s: bounds %v %v, want %v %v", name, got.Data.Min, got.Data.Max, want.Data.Min, want.Data.Max)
+	}
+}
+
+func TestEncodeDecode(t *testing.T) {
+	for _, n := range []int{0, 3, 1000} {
+		tree := rbush.New(9)
+		tree.Load(randomPoints(n))
+		var buf bytes.Buffer
+		if err := tree.Encode(&buf, pointCodec{}); err != nil {
+			t.Fatal(err)
+		}
+		data := buf.Bytes()
+		decoded, err := rbush.Decode(bufio.NewReader(bytes.NewReader(data)), pointCodec{})
+		if err != nil {
+			t.Fatalf("%d points: %v", n, err)
+		}
+		sameTree(t, "binary", decoded, tree)
+		if decoded.Len() != n {
+			t.Errorf("decoded Len() = %d, want %d", decoded.Len(), n)
+		}
+
+		// the decoded tree is a working tree
+		decoded.Insert(point{50, 50})
+		decoded.Remove(point{50, 50})
+		sameTree(t, "binary after update", decoded, tree)
+
+		if n > 0 {
+			if _, err := rbush.Decode(bytes.NewReader(data[:len(data)-5]), pointCodec{}); err != io.ErrUnexpectedEOF {
+				t.Errorf("truncated: err = %v", err)
+			}
+		}
+	}
+
+	if _, err := rbush.Decode(bytes.NewReader([]byte("RBUSH\x00\x00\x00\x00")), pointCodec{}); !errors.Is(err, rbush.ErrFormat) {
+		t.Errorf("bad magic: err = %v", err)
+	}
+}
+
+// header returns the start of an encoding with the given node size, followed
+// by a root node header
+func header(maxEntries, height, n uint32) []byte {
+	var buf bytes.Buffer
+	buf.WriteString("RBSH\x01")
+	binary.Write(&buf, binary.LittleEndian, maxEntries)
+	binary.Write(&buf, binary.LittleEndian, [4]float64{0, 0, 1, 1})
+	binary.Write(&buf, binary.LittleEndian, [2]uint32{height, n})
+	return buf.Bytes()
+}
+
+func TestDecodeCorrupt(t *testing.T) {
+	tests := []struct {
+		name string
+		data []byte
+		err  error
+	}{
+		{"huge nodes", header(1<<31-1, 1, 0), rbush.ErrFormat},
+		{"too many children", header(9, 2, 10), rbush.ErrFormat},
+		{"children past the end", header(1<<16, 2, 1<<16), io.ErrUnexpectedEOF},
+		{"items past the end", header(1<<16, 1, 1<<16), io.ErrUnexpectedEOF},
+	}
+	for _, tt := range tests {
+		if _, err := rbush.Decode(bytes.NewReader(tt.data), pointCodec{}); !errors.Is(err, tt.err) {
+			t.Errorf("%s: err = %v, want %v", tt.name, err, tt.err)
+		}
+	}
+}
+
+func TestBinary(t *testing.T) {
+	tree := rbush.New(9)
+	tree.Load(randomPoints(500))
+
+	data, err := (&rbush.Binary{Tree: tree, Codec: pointCodec{}}).MarshalBinary()
+	if err != nil {
+		t.Fatal(err)
+	}
+	b := rbush.Binary{Codec: pointCodec{}}
+	if err := b.UnmarshalBinary(data); err != nil {
+		t.Fatal(err)
+	}
+	sameTree(t, "UnmarshalBinary", b.Tree, tree)
+	if err := b.UnmarshalBinary(append(data, 0)); err != rbush.ErrFormat {
+		t.Errorf("trailing data: err = %v", err)
+	}
+
+	var buf bytes.Buffer
+	n, err := (&rbush.Binary{Tree: tree, Codec: pointCodec{}}).WriteTo(&buf)
+	if err != nil || n != int64(len(data)) || !bytes.Equal(buf.Bytes(), data) {
+		t.Errorf("WriteTo() = %d, %v", n, err)
+	}
+	b = rbush.Binary{Codec: pointCodec{}}
+	n, err = b.ReadFrom(&buf)
+	if err != nil || n != int64(len(data)) {
+		t.Fatalf("ReadFrom() = %d, %v", n, err)
+	}
+	sameTree(t, "ReadFrom", b.Tree, tree)
+}
+
+func TestJSON(t *testing.T) {
+	tree := rbush.New(4)
+	tree.Load(randomPoints(100))
+	data, err := tree.ToJSON()
+	if err != nil {
+		t.Fatal(err)
+	}
+	decoded := rbush.New(4)
+	if err := decoded.FromJSON(data, decodePoint); err != nil {
+		t.Fatal(err)
+	}
+	sameTree(t, "json", decoded, tree)
+	if decoded.Len() != 100 {
+		t.Errorf("decoded Len() = %d, want 100", decoded.Len())
+	}
+
+	data, err = rbush.New(9).ToJSON()
+	if err != nil {
+		t.Fatal(err)
+	}
+	const empty = `{"children":[],"height":1,"leaf":true,"minX":null,"minY":null,"maxX":null,"maxY":null}`
+	if string(data) != empty {
+		t.Errorf("empty tree: %s", data)
+	}
+}
+
+func TestFromJSONRBushJS(t *testing.T) {
+	// written by toJSON in the JavaScript rbush, with points as items
+	const data = `{"children":[
+		{"children":[[1,1],[2,3],[3,2]],"height":1,"leaf":true,"minX":1,"minY":1,"maxX":3,"maxY":3},
+		{"children":[[7,8],[9,9]],"height":1,"leaf":true,"minX":7,"minY":8,"maxX":9,"maxY":9}
+	],"height":2,"leaf":false,"minX":1,"minY":1,"maxX":9,"maxY":9}`
+
+	tree := rbush.New(4)
+	if err := tree.FromJSON([]byte(data), decodePoint); err != nil {
+		t.Fatal(err)
+	}
+	got := sortedPoints(tree, [2]float64{2, 2}, [2]float64{8, 8})
+	if len(got) != 3 || got[0] != (point{2, 3}) || got[1] != (point{3, 2}) || got[2] != (point{7, 8}) {
+		t.Errorf("found %v", got)
+	}
+	if tree.Data.Min != [2]float64{1, 1} || tree.Data.Max != [2]float64{9, 9} {
+		t.Errorf("bounds %v %v", tree.Data.Min, tree.Data.Max)
+	}
+
+	const bad = `{"children":[[1,1]],"height":2,"leaf":false,"minX":1,"minY":1,"maxX":1,"maxY":1}`
+	if err := rbush.New(4).FromJSON([]byte(bad), decodePoint); err == nil {
+		t.Error("accepted points as nodes")
+	}
+}
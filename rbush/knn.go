@@ -0,0 +1,98 @@
+package rbush
+
+import (
+	"github.com/tidwall/tinyqueue"
+)
+
+type knnEntry struct {
+	child interface{}
+	item  bool
+	dist  float64
+}
+
+// impl tinyqueue.Item
+func (a *knnEntry) Less(b tinyqueue.Item) bool {
+	return a.dist < b.(*knnEntry).dist
+}
+
+// NearestIter visits items in order of increasing distance with a best-first
+// traversal of the tree. boxDist must return a lower bound of itemDist for
+// every item inside the node's bounding box. Nodes and items farther than
+// maxDist are skipped. Iteration stops when iter returns false, in which case
+// NearestIter returns false.
+func (tr *RBush) NearestIter(boxDist func(node *TreeNode) float64, itemDist func(item Item) float64, maxDist float64, iter func(item Item, dist float64) bool) bool {
+	queue := tinyqueue.New(nil)
+	node := tr.Data
+
+	for node != nil {
+		for _, child := range node.Children {
+			var dist float64
+			if node.Leaf {
+				dist = itemDist(child.(Item))
+			} else {
+				dist = boxDist(child.(*TreeNode))
+			}
+			if dist > maxDist {
+				// skip the node if it's farther than we ever need
+				continue
+			}
+			queue.Push(&knnEntry{child: child, item: node.Leaf, dist: dist})
+		}
+
+		// pop items while they are closer than any node left in the queue
+		for queue.Len() > 0 && queue.Peek().(*knnEntry).item {
+			e := queue.Pop().(*knnEntry)
+			if !iter(e.child.(Item), e.dist) {
+				return false
+			}
+		}
+
+		if e := queue.Pop(); e != nil {
+			node = e.(*knnEntry).child.(*TreeNode)
+		} else {
+			node = nil
+		}
+	}
+	return true
+}
+
+// Nearest returns up to k items closest to the point, ordered by Euclidean
+// distance to their bounding boxes. Items farther than maxDist or rejected by
+// filter are left out. A k or maxDist of zero or less means no limit, and a
+// nil filter accepts every item.
+func (tr *RBush) Nearest(point [2]float64, k int, maxDist float64, filter func(item Item) bool) []Item {
+	limit := mathInfPos
+	if maxDist > 0 {
+		limit = maxDist * maxDist
+	}
+	var result []Item
+	tr.NearestIter(func(node *TreeNode) float64 {
+		return sqBoxDist(point, node.Min, node.Max)
+	}, func(item Item) float64 {
+		min, max := item.Rect()
+		return sqBoxDist(point, min, max)
+	}, limit, func(item Item, dist float64) bool {
+		if filter == nil || filter(item) {
+			result = append(result, item)
+		}
+		return k <= 0 || len(result) < k
+	})
+	return result
+}
+
+// square distance from a point to a bounding box
+func sqBoxDist(p, min, max [2]float64) float64 {
+	dx := axisDist(p[0], min[0], max[0])
+	dy := axisDist(p[1], min[1], max[1])
+	return dx*dx + dy*dy
+}
+
+func axisDist(k, min, max float64) float64 {
+	if k < min {
+		return min - k
+	}
+	if k <= max {
+		return 0
+	}
+	return k - max
+}
@@ -0,0 +1,160 @@
+package rbush_test
+
+import (
+	"math"
+	"math/rand"
+	"sort"
+	"testing"
+
+	"github.com/wsw0108/concaveman-go/internal/testutil"
+	"github.com/wsw0108/concaveman-go/rbush"
+)
+
+type point [2]float64
+
+func (p point) Rect() (min, max [2]float64) {
+	return p, p
+}
+
+func randomPoints(n int) []rbush.Item {
+	items := make([]rbush.Item, n)
+	for i, p := range testutil.Points[point](rand.New(rand.NewSource(42)), n, 100) {
+		items[i] = p
+	}
+	return items
+}
+
+func dist(p, q [2]float64) float64 {
+	return math.Hypot(p[0]-q[0], p[1]-q[1])
+}
+
+// nearest by brute force
+func bruteNearest(items []rbush.Item, q [2]float64, k int, maxDist float64) []float64 {
+	var dists []float64
+	for _, item := range items {
+		if d := dist(item.(point), q); maxDist <= 0 || d <= maxDist {
+			dists = append(dists, d)
+		}
+	}
+	sort.Float64s(dists)
+	if k > 0 && len(dists) > k {
+		dists = dists[:k]
+	}
+	return dists
+}
+
+func TestNearest(t *testing.T) {
+	items := randomPoints(1000)
+	tree := rbush.New(9)
+	tree.Load(append([]rbush.Item(nil), items...))
+
+	tests := []struct {
+		name    string
+		q       [2]float64
+		k       int
+		maxDist float64
+	}{
+		{"k", [2]float64{50, 50}, 10, 0},
+		{"outside", [2]float64{-20, 130}, 5, 0},
+		{"max distance", [2]float64{25, 75}, 0, 8},
+		{"k and max distance", [2]float64{25, 75}, 3, 8},
+		{"all", [2]float64{0, 0}, 0, 0},
+	}
+	for _, tt := range tests {
+		got := tree.Nearest(tt.q, tt.k, tt.maxDist, nil)
+		want := bruteNearest(items, tt.q, tt.k, tt.maxDist)
+		if len(got) != len(want) {
+			t.Errorf("%s: got %d items, want %d", tt.name, len(got), len(want))
+			continue
+		}
+		for i, item := range got {
+			if d := dist(item.(point), tt.q); d != want[i] {
+				t.Errorf("%s: item %d at distance %v, want %v", tt.name, i, d, want[i])
+			}
+		}
+	}
+
+	if got := rbush.New(9).Nearest([2]float64{0, 0}, 3, 0, nil); len(got) != 0 {
+		t.Errorf("empty tree: got %d items", len(got))
+	}
+}
+
+func TestNearestFilter(t *testing.T) {
+	items := randomPoints(500)
+	tree := rbush.New(4)
+	for _, item := range items {
+		tree.Insert(item)
+	}
+	left := func(item rbush.Item) bool { return item.(point)[0] < 50 }
+	got := tree.Nearest([2]float64{90, 50}, 4, 0, left)
+	if len(got) != 4 {
+		t.Fatalf("got %d items, want 4", len(got))
+	}
+	var filtered []rbush.Item
+	for _, item := range items {
+		if left(item) {
+			filtered = append(filtered, item)
+		}
+	}
+	want := bruteNearest(filtered, [2]float64{90, 50}, 4, 0)
+	for i, item := range got {
+		if !left(item) {
+			t.Errorf("item %v does not pass the filter", item)
+		}
+		if d := dist(item.(point), [2]float64{90, 50}); d != want[i] {
+			t.Errorf("item %d at distance %v, want %v", i, d, want[i])
+		}
+	}
+}
+
+func TestNearestIter(t *testing.T) {
+	items := randomPoints(1000)
+	tree := rbush.New(16)
+	tree.Load(append([]rbush.Item(nil), items...))
+
+	// distances to the horizontal line y = 30
+	boxDist := func(node *rbush.TreeNode) float64 {
+		if node.Min[1] <= 30 && 30 <= node.Max[1] {
+			return 0
+		}
+		return math.Min(math.Abs(node.Min[1]-30), math.Abs(node.Max[1]-30))
+	}
+	itemDist := func(item rbush.Item) float64 {
+		return math.Abs(item.(point)[1] - 30)
+	}
+
+	var n int
+	last := -1.0
+	done := tree.NearestIter(boxDist, itemDist, 5, func(item rbush.Item, d float64) bool {
+		if d < last {
+			t.Errorf("distance %v after %v", d, last)
+		}
+		if d != itemDist(item) || d > 5 {
+			t.Errorf("unexpected distance %v for %v", d, item)
+		}
+		last = d
+		n++
+		return true
+	})
+	if !done {
+		t.Error("NearestIter stopped early")
+	}
+	var want int
+	for _, item := range items {
+		if itemDist(item) <= 5 {
+			want++
+		}
+	}
+	if n != want {
+		t.Errorf("visited %d items, want %d", n, want)
+	}
+
+	n = 0
+	done = tree.NearestIter(boxDist, itemDist, math.Inf(1), func(rbush.Item, float64) bool {
+		n++
+		return n < 3
+	})
+	if done || n != 3 {
+		t.Errorf("stop: done = %v after %d items", done, n)
+	}
+}
@@ -452,7 +452,7 @@ func (tr *RBush) allDistMargin(node *TreeNode, m, M int, axis int) float64 {
 		}
 		for i = M - m - 1; i >= m; i-- {
 			fillBBox(node.Children[i].(Item), &child)
-			leftBBox.extend(&child)
+			rightBBox.extend(&child)
 			margin += rightBBox.margin()
 		}
 	} else {
@@ -463,7 +463,7 @@ func (tr *RBush) allDistMargin(node *TreeNode, m, M int, axis int) float64 {
 		}
 		for i = M - m - 1; i >= m; i-- {
 			child := node.Children[i].(*TreeNode)
-			leftBBox.extend(child)
+			rightBBox.extend(child)
 			margin += rightBBox.margin()
 		}
 	}
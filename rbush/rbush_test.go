@@ -0,0 +1,32 @@
+package rbush_test
+
+import (
+	"reflect"
+	"sort"
+	"testing"
+
+	"github.com/wsw0108/concaveman-go/rbush"
+)
+
+func TestSplitAxis(t *testing.T) {
+	// The margins of the candidate splits sum to 31 along x and 32 along y.
+	// When the right boxes were not grown both sums came to 24, so the node
+	// was split along y into {(1, 1), (6, 2), (9, 3)} and {(2, 6), (4, 8)}.
+	tree := rbush.New(4)
+	for _, p := range []point{{1, 1}, {9, 3}, {4, 8}, {2, 6}, {6, 2}} {
+		tree.Insert(p)
+	}
+	var leaves [][]point
+	for _, child := range tree.Data.Children {
+		var leaf []point
+		for _, item := range child.(*rbush.TreeNode).Children {
+			leaf = append(leaf, item.(point))
+		}
+		sort.Slice(leaf, func(i, j int) bool { return leaf[i][0] < leaf[j][0] })
+		leaves = append(leaves, leaf)
+	}
+	want := [][]point{{{1, 1}, {2, 6}, {4, 8}}, {{6, 2}, {9, 3}}}
+	if !reflect.DeepEqual(leaves, want) {
+		t.Errorf("leaves = %v, want %v", leaves, want)
+	}
+}
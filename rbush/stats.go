@@ -0,0 +1,148 @@
+package rbush
+
+// Len returns the number of items in the tree. It counts them in the leaves,
+// so that it stays right when Data is replaced, which takes time proportional
+// to the number of nodes.
+func (tr *RBush) Len() int {
+	return countItems(tr.Data)
+}
+
+// All calls iter for every item in the tree until iter returns false, in
+// which case All returns false.
+func (tr *RBush) All(iter func(item Item) bool) bool {
+	return all(tr.Data, iter)
+}
+
+func all(node *TreeNode, iter func(item Item) bool) bool {
+	for _, child := range node.Children {
+		if node.Leaf {
+			if !iter(child.(Item)) {
+				return false
+			}
+		} else if !all(child.(*TreeNode), iter) {
+			return false
+		}
+	}
+	return true
+}
+
+// Bounds returns the bounding box of all items. For an empty tree min is
+// +Inf and max is -Inf.
+func (tr *RBush) Bounds() (min, max [2]float64) {
+	return tr.Data.Min, tr.Data.Max
+}
+
+// Height returns the number of levels of the tree, 1 for a tree that is a
+// single leaf.
+func (tr *RBush) Height() int {
+	return tr.Data.height
+}
+
+// Collides reports whether any item intersects bbox, which is faster than
+// searching for them.
+func (tr *RBush) Collides(bbox Item) bool {
+	if bbox == nil {
+		panic("bbox is nil")
+	}
+	min, max := bbox.Rect()
+	return !tr.searchBBox(min, max, func(Item) bool {
+		return false
+	})
+}
+
+// Stats describes the shape of a tree, for monitoring its health.
+type Stats struct {
+	// Items is the number of items found in the leaves, which should be Len.
+	Items int
+	// Nodes is the number of nodes, the root included.
+	Nodes int
+	// Height is the number of levels, as returned by RBush.Height.
+	Height int
+	// MaxEntries is the maximum number of children of a node.
+	MaxEntries int
+	// FillFactor is the mean number of children of the nodes other than the
+	// root relative to MaxEntries; bulk loading gives the highest one.
+	FillFactor float64
+	// Levels describes each level of the tree from the root down.
+	Levels []LevelStats
+	// LeafDepths counts the leaves by their depth, the root being at depth
+	// 0; in a healthy tree all leaves are at depth Height-1.
+	LeafDepths map[int]int
+}
+
+// LevelStats describes the nodes at one depth of a tree.
+type LevelStats struct {
+	// Nodes is the number of nodes at this depth.
+	Nodes int
+	// Entries is the total number of children of these nodes.
+	Entries int
+	// MinEntries and MaxEntries are the fewest and most children of a node.
+	MinEntries, MaxEntries int
+	// FillFactor is Entries per node relative to the tree's MaxEntries.
+	FillFactor float64
+}
+
+// Stats walks the whole tree and reports its shape.
+func (tr *RBush) Stats() Stats {
+	s := Stats{
+		Height:     tr.Data.height,
+		MaxEntries: tr.maxEntries,
+		LeafDepths: make(map[int]int),
+	}
+	tr.stats(tr.Data, 0, &s)
+
+	var nodes, entries int
+	for depth := range s.Levels {
+		l := &s.Levels[depth]
+		l.FillFactor = float64(l.Entries) / float64(l.Nodes*tr.maxEntries)
+		if depth > 0 {
+			nodes += l.Nodes
+			entries += l.Entries
+		}
+	}
+	if nodes > 0 {
+		s.FillFactor = float64(entries) / float64(nodes*tr.maxEntries)
+	} else {
+		// the root is the only node
+		s.FillFactor = s.Levels[0].FillFactor
+	}
+	return s
+}
+
+func (tr *RBush) stats(node *TreeNode, depth int, s *Stats) {
+	if depth == len(s.Levels) {
+		s.Levels = append(s.Levels, LevelStats{MinEntries: len(node.Children)})
+	}
+	l := &s.Levels[depth]
+	n := len(node.Children)
+	l.Nodes++
+	l.Entries += n
+	if n < l.MinEntries {
+		l.MinEntries = n
+	}
+	if n > l.MaxEntries {
+		l.MaxEntries = n
+	}
+	s.Nodes++
+
+	if node.Leaf {
+		s.Items += n
+		s.LeafDepths[depth]++
+		return
+	}
+	for _, child := range node.Children {
+		tr.stats(child.(*TreeNode), depth+1, s)
+	}
+}
+
+// countItems returns the number of items below node
+func countItems(node *TreeNode) int {
+	if node.Leaf {
+		return len(node.Children)
+	}
+	var n int
+	for _, child := range node.Children {
+		n += countItems(child.(*TreeNode))
+	}
+	return n
+}
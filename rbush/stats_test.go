@@ -0,0 +1,130 @@
+package rbush_test
+
+import (
+	"math"
+	"testing"
+
+	"github.com/wsw0108/concaveman-go/internal/testutil"
+	"github.com/wsw0108/concaveman-go/rbush"
+)
+
+func TestLenAll(t *testing.T) {
+	items := randomPoints(500)
+	tree := rbush.New(9)
+	if tree.Len() != 0 || tree.Height() != 1 {
+		t.Errorf("empty tree: Len() = %d, Height() = %d", tree.Len(), tree.Height())
+	}
+	if min, max := tree.Bounds(); !math.IsInf(min[0], 1) || !math.IsInf(max[0], -1) {
+		t.Errorf("empty tree: bounds %v %v", min, max)
+	}
+
+	tree.Load(append([]rbush.Item(nil), items[:300]...))
+	tree.Load(append([]rbush.Item(nil), items[300:302]...))
+	for _, item := range items[302:] {
+		tree.Insert(item)
+	}
+	if tree.Len() != 500 {
+		t.Errorf("Len() = %d, want 500", tree.Len())
+	}
+
+	seen := make(map[point]int)
+	tree.All(func(item rbush.Item) bool {
+		seen[item.(point)]++
+		return true
+	})
+	for _, item := range items {
+		if seen[item.(point)] != 1 {
+			t.Fatalf("All visited %v %d times", item, seen[item.(point)])
+		}
+	}
+	var n int
+	if tree.All(func(rbush.Item) bool { n++; return n < 5 }) || n != 5 {
+		t.Errorf("All did not stop, visited %d items", n)
+	}
+
+	min, max := tree.Bounds()
+	for _, item := range items {
+		p := item.(point)
+		if p[0] < min[0] || p[1] < min[1] || p[0] > max[0] || p[1] > max[1] {
+			t.Fatalf("%v outside bounds %v %v", p, min, max)
+		}
+	}
+
+	for _, item := range items[:200] {
+		tree.Remove(item)
+	}
+	tree.Remove(point{-1, -1})
+	if tree.Len() != 300 {
+		t.Errorf("after removal: Len() = %d, want 300", tree.Len())
+	}
+	for _, item := range items[200:] {
+		tree.Remove(item)
+	}
+	if tree.Len() != 0 {
+		t.Errorf("emptied tree: Len() = %d", tree.Len())
+	}
+
+	// Data is exported, so a tree may be swapped in
+	other := rbush.New(9)
+	other.Load(randomPoints(42))
+	tree.Data = other.Data
+	if tree.Len() != 42 {
+		t.Errorf("replaced tree: Len() = %d, want 42", tree.Len())
+	}
+}
+
+func TestCollides(t *testing.T) {
+	tree := rbush.New(4)
+	tree.Load(randomPoints(200))
+	if !tree.Collides(testutil.Box{Min: [2]float64{0, 0}, Max: [2]float64{100, 100}}) {
+		t.Error("no collision within the whole area")
+	}
+	if tree.Collides(testutil.Box{Min: [2]float64{101, 101}, Max: [2]float64{200, 200}}) {
+		t.Error("collision outside the area")
+	}
+}
+
+func TestStats(t *testing.T) {
+	tree := rbush.New(9)
+	tree.Load(randomPoints(1000))
+	s := tree.Stats()
+	if s.Items != 1000 || s.Items != tree.Len() || s.Height != tree.Height() || s.MaxEntries != 9 {
+		t.Errorf("stats %+v", s)
+	}
+	if len(s.Levels) != s.Height || s.Levels[0].Nodes != 1 {
+		t.Errorf("levels %+v", s.Levels)
+	}
+	if len(s.LeafDepths) != 1 || s.LeafDepths[s.Height-1] != s.Levels[s.Height-1].Nodes {
+		t.Errorf("leaf depths %v", s.LeafDepths)
+	}
+	var nodes int
+	for depth, l := range s.Levels {
+		nodes += l.Nodes
+		if l.MaxEntries > 9 || l.FillFactor <= 0 || l.FillFactor > 1 {
+			t.Errorf("level %d: %+v", depth, l)
+		}
+		if depth > 0 && l.Entries > 0 && l.MinEntries < 1 {
+			t.Errorf("level %d: empty node", depth)
+		}
+	}
+	if nodes != s.Nodes || s.Levels[s.Height-1].Entries != 1000 {
+		t.Errorf("%d nodes, stats %+v", nodes, s)
+	}
+	if s.FillFactor < 0.7 || s.FillFactor > 1 {
+		t.Errorf("bulk loaded fill factor %v", s.FillFactor)
+	}
+
+	// inserting one by one fills the nodes less
+	inserted := rbush.New(9)
+	for _, item := range randomPoints(1000) {
+		inserted.Insert(item)
+	}
+	if f := inserted.Stats().FillFactor; f >= s.FillFactor {
+		t.Errorf("fill factor %v after inserts, %v after loading", f, s.FillFactor)
+	}
+
+	empty := rbush.New(9).Stats()
+	if empty.Items != 0 || empty.Nodes != 1 || empty.FillFactor != 0 {
+		t.Errorf("empty tree: %+v", empty)
+	}
+}
@@ -0,0 +1,641 @@
+package rbush
+
+import (
+	"math"
+	"sort"
+
+	"github.com/tidwall/tinyqueue"
+)
+
+// Tree is an R-tree of values of type T, located by the bounding boxes that
+// its bbox function returns for them. It works like RBush but stores the
+// items themselves rather than Item interface values, so no type assertions
+// or boxing are needed. Remove compares items with ==.
+type Tree[T comparable] struct {
+	maxEntries int
+	minEntries int
+	bbox       func(item T) (min, max [2]float64)
+	root       *tnode[T]
+	reusePath  []*tnode[T]
+}
+
+type tnode[T comparable] struct {
+	min, max [2]float64
+	children []*tnode[T]
+	items    []T
+	leaf     bool
+	height   int
+}
+
+// NewTree returns an empty tree with up to maxEntries children per node that
+// locates items with the bbox function.
+func NewTree[T comparable](maxEntries int, bbox func(item T) (min, max [2]float64)) *Tree[T] {
+	tr := &Tree[T]{bbox: bbox}
+	tr.maxEntries = int(mathMax(4, float64(maxEntries)))
+	tr.minEntries = int(mathMax(2, math.Ceil(float64(tr.maxEntries)*0.4)))
+	tr.Clear()
+	return tr
+}
+
+func newTNode[T comparable](leaf bool) *tnode[T] {
+	return &tnode[T]{
+		min:    [2]float64{mathInfPos, mathInfPos},
+		max:    [2]float64{mathInfNeg, mathInfNeg},
+		leaf:   leaf,
+		height: 1,
+	}
+}
+
+func (n *tnode[T]) len() int {
+	if n.leaf {
+		return len(n.items)
+	}
+	return len(n.children)
+}
+
+func (n *tnode[T]) extend(min, max [2]float64) {
+	for i := 0; i < 2; i++ {
+		n.min[i] = mathMin(n.min[i], min[i])
+		n.max[i] = mathMax(n.max[i], max[i])
+	}
+}
+
+// Clear removes all items from the tree.
+func (tr *Tree[T]) Clear() {
+	tr.root = newTNode[T](true)
+}
+
+// Search calls iter for every item whose bounding box intersects the box
+// (min, max) until iter returns false, in which case Search returns false.
+func (tr *Tree[T]) Search(min, max [2]float64, iter func(item T) bool) bool {
+	if !boxIntersects(min, max, tr.root.min, tr.root.max) {
+		return true
+	}
+	return tr.search(tr.root, min, max, iter)
+}
+
+func (tr *Tree[T]) search(node *tnode[T], min, max [2]float64, iter func(item T) bool) bool {
+	if node.leaf {
+		for _, item := range node.items {
+			imin, imax := tr.bbox(item)
+			if boxIntersects(min, max, imin, imax) {
+				if !iter(item) {
+					return false
+				}
+			}
+		}
+	} else {
+		for _, child := range node.children {
+			if boxIntersects(min, max, child.min, child.max) {
+				if !tr.search(child, min, max, iter) {
+					return false
+				}
+			}
+		}
+	}
+	return true
+}
+
+// NearestIter visits items in order of increasing distance like
+// RBush.NearestIter, with boxDist called on the bounding boxes of nodes.
+func (tr *Tree[T]) NearestIter(boxDist func(min, max [2]float64) float64, itemDist func(item T) float64, maxDist float64, iter func(item T, dist float64) bool) bool {
+	queue := tinyqueue.New(nil)
+	node := tr.root
+
+	for node != nil {
+		if node.leaf {
+			for _, item := range node.items {
+				dist := itemDist(item)
+				if dist > maxDist {
+					continue
+				}
+				queue.Push(&treeKNNEntry[T]{item: item, dist: dist})
+			}
+		} else {
+			for _, child := range node.children {
+				dist := boxDist(child.min, child.max)
+				if dist > maxDist {
+					// skip the node if it's farther than we ever need
+					continue
+				}
+				queue.Push(&treeKNNEntry[T]{node: child, dist: dist})
+			}
+		}
+
+		// pop items while they are closer than any node left in the queue
+		for queue.Len() > 0 && queue.Peek().(*treeKNNEntry[T]).node == nil {
+			e := queue.Pop().(*treeKNNEntry[T])
+			if !iter(e.item, e.dist) {
+				return false
+			}
+		}
+
+		if e := queue.Pop(); e != nil {
+			node = e.(*treeKNNEntry[T]).node
+		} else {
+			node = nil
+		}
+	}
+	return true
+}
+
+type treeKNNEntry[T comparable] struct {
+	node *tnode[T]
+	item T
+	dist float64
+}
+
+// impl tinyqueue.Item
+func (a *treeKNNEntry[T]) Less(b tinyqueue.Item) bool {
+	return a.dist < b.(*treeKNNEntry[T]).dist
+}
+
+// Nearest returns up to k items closest to the point like RBush.Nearest.
+func (tr *Tree[T]) Nearest(point [2]float64, k int, maxDist float64, filter func(item T) bool) []T {
+	limit := mathInfPos
+	if maxDist > 0 {
+		limit = maxDist * maxDist
+	}
+	var result []T
+	tr.NearestIter(func(min, max [2]float64) float64 {
+		return sqBoxDist(point, min, max)
+	}, func(item T) float64 {
+		min, max := tr.bbox(item)
+		return sqBoxDist(point, min, max)
+	}, limit, func(item T, dist float64) bool {
+		if filter == nil || filter(item) {
+			result = append(result, item)
+		}
+		return k <= 0 || len(result) < k
+	})
+	return result
+}
+
+// Insert adds an item to the tree.
+func (tr *Tree[T]) Insert(item T) {
+	min, max := tr.bbox(item)
+	tr.insert(min, max, item, nil, tr.root.height-1)
+}
+
+// Load bulk-inserts the items, which is much faster than inserting them one
+// by one. It reorders the items slice.
+func (tr *Tree[T]) Load(items []T) {
+	if len(items) < tr.minEntries {
+		for _, item := range items {
+			tr.Insert(item)
+		}
+		return
+	}
+
+	node := tr.build(items, 0, len(items)-1, 0)
+
+	if tr.root.len() == 0 {
+		tr.root = node
+	} else if tr.root.height == node.height {
+		tr.splitRoot(tr.root, node)
+	} else {
+		if tr.root.height < node.height {
+			tr.root, node = node, tr.root
+		}
+		tr.insert(node.min, node.max, *new(T), node, tr.root.height-node.height-1)
+	}
+}
+
+// Remove removes one occurrence of the item from the tree.
+func (tr *Tree[T]) Remove(item T) {
+	min, max := tr.bbox(item)
+	path := tr.reusePath[:0]
+	if boxContains(tr.root.min, tr.root.max, min, max) {
+		path, _ = tr.remove(tr.root, item, min, max, path)
+	}
+	tr.reusePath = path
+}
+
+// remove looks for the item depth-first below node and removes it, returning
+// the path so it can be reused
+func (tr *Tree[T]) remove(node *tnode[T], item T, min, max [2]float64, path []*tnode[T]) ([]*tnode[T], bool) {
+	path = append(path, node)
+	if node.leaf {
+		for i, it := range node.items {
+			if it == item {
+				// item found, remove the item and condense tree upwards
+				copy(node.items[i:], node.items[i+1:])
+				node.items[len(node.items)-1] = *new(T)
+				node.items = node.items[:len(node.items)-1]
+				tr.condense(path)
+				return path, true
+			}
+		}
+		return path[:len(path)-1], false
+	}
+	for _, child := range node.children {
+		if boxContains(child.min, child.max, min, max) {
+			var found bool
+			if path, found = tr.remove(child, item, min, max, path); found {
+				return path, true
+			}
+		}
+	}
+	return path[:len(path)-1], false
+}
+
+func (tr *Tree[T]) build(items []T, left, right int, height int) *tnode[T] {
+	N := right - left + 1
+	M := tr.maxEntries
+
+	if N <= M {
+		// reached leaf level; return leaf
+		node := newTNode[T](true)
+		node.items = append(make([]T, 0, N), items[left:right+1]...)
+		tr.calcBBox(node)
+		return node
+	}
+
+	if height <= 0 {
+		// target height of the bulk-loaded tree
+		height = int(math.Ceil(math.Log(float64(N)) / math.Log(float64(M))))
+
+		// target number of root entries to maximize storage utilization
+		M = int(math.Ceil(float64(N) / math.Pow(float64(M), float64(height-1))))
+	}
+
+	node := newTNode[T](false)
+	node.height = height
+
+	// split the items into M mostly square tiles
+
+	N2 := int(math.Ceil(float64(N) / float64(M)))
+	N1 := int(float64(N2) * math.Ceil(math.Sqrt(float64(M))))
+
+	tr.multiSelect(items, left, right, N1, 0)
+
+	for i := left; i <= right; i += N1 {
+		right2 := int(mathMin(float64(i+N1-1), float64(right)))
+
+		tr.multiSelect(items, i, right2, N2, 1)
+
+		for j := i; j <= right2; j += N2 {
+			right3 := int(mathMin(float64(j+N2-1), float64(right2)))
+
+			// pack each entry recursively
+			node.children = append(node.children, tr.build(items, j, right3, height-1))
+		}
+	}
+
+	tr.calcBBox(node)
+
+	return node
+}
+
+func (tr *Tree[T]) chooseSubtree(min, max [2]float64, node *tnode[T], level int, path []*tnode[T]) (*tnode[T], []*tnode[T]) {
+	for {
+		path = append(path, node)
+		if node.leaf || len(path)-1 == level {
+			break
+		}
+		minArea := mathInfPos
+		minEnlargement := mathInfPos
+		var targetNode *tnode[T]
+		for _, child := range node.children {
+			area := boxArea(child.min, child.max)
+			enlargement := enlargedArea(min, max, child.min, child.max) - area
+			if enlargement < minEnlargement {
+				minEnlargement = enlargement
+				if area < minArea {
+					minArea = area
+				}
+				targetNode = child
+			} else if enlargement == minEnlargement {
+				if area < minArea {
+					minArea = area
+					targetNode = child
+				}
+			}
+		}
+		if targetNode != nil {
+			node = targetNode
+		} else if len(node.children) > 0 {
+			node = node.children[0]
+		} else {
+			panic("node will be nil")
+		}
+	}
+	return node, path
+}
+
+// insert adds either the item or, if child is not nil, the subtree at the
+// given level
+func (tr *Tree[T]) insert(min, max [2]float64, item T, child *tnode[T], level int) {
+	tr.reusePath = tr.reusePath[:0]
+	node, insertPath := tr.chooseSubtree(min, max, tr.root, level, tr.reusePath)
+	if child != nil {
+		node.children = append(node.children, child)
+	} else {
+		node.items = append(node.items, item)
+	}
+	node.extend(min, max)
+	for level >= 0 {
+		if insertPath[level].len() > tr.maxEntries {
+			tr.split(insertPath, level)
+			level--
+		} else {
+			break
+		}
+	}
+	// adjust bboxes along the insertion path
+	for i := level; i >= 0; i-- {
+		insertPath[i].extend(min, max)
+	}
+	tr.reusePath = insertPath
+}
+
+func (tr *Tree[T]) split(insertPath []*tnode[T], level int) {
+	node := insertPath[level]
+	M := node.len()
+	m := tr.minEntries
+
+	tr.chooseSplitAxis(node, m, M)
+	splitIndex := tr.chooseSplitIndex(node, m, M)
+
+	newNode := newTNode[T](node.leaf)
+	newNode.height = node.height
+	if node.leaf {
+		newNode.items = append([]T(nil), node.items[splitIndex:]...)
+		node.items = node.items[:splitIndex]
+	} else {
+		newNode.children = append([]*tnode[T](nil), node.children[splitIndex:]...)
+		node.children = node.children[:splitIndex]
+	}
+
+	tr.calcBBox(node)
+	tr.calcBBox(newNode)
+
+	if level != 0 {
+		insertPath[level-1].children = append(insertPath[level-1].children, newNode)
+	} else {
+		tr.splitRoot(node, newNode)
+	}
+}
+
+func (tr *Tree[T]) splitRoot(node, newNode *tnode[T]) {
+	tr.root = newTNode[T](false)
+	tr.root.children = []*tnode[T]{node, newNode}
+	tr.root.height = node.height + 1
+	tr.calcBBox(tr.root)
+}
+
+func (tr *Tree[T]) chooseSplitIndex(node *tnode[T], m, M int) int {
+	index := -1
+	minOverlap := mathInfPos
+	minArea := mathInfPos
+
+	for i := m; i <= M-m; i++ {
+		min1, max1 := tr.distBBox(node, 0, i)
+		min2, max2 := tr.distBBox(node, i, M)
+
+		overlap := intersectionArea(min1, max1, min2, max2)
+		area := boxArea(min1, max1) + boxArea(min2, max2)
+
+		// choose distribution with minimum overlap
+		if overlap < minOverlap {
+			minOverlap = overlap
+			index = i
+
+			if area < minArea {
+				minArea = area
+			}
+		} else if overlap == minOverlap {
+			// otherwise choose distribution with minimum area
+			if area < minArea {
+				minArea = area
+				index = i
+			}
+		}
+	}
+	if index >= 0 {
+		return index
+	}
+	return M - m
+}
+
+func (tr *Tree[T]) chooseSplitAxis(node *tnode[T], m, M int) {
+	xMargin := tr.allDistMargin(node, m, M, 0)
+	yMargin := tr.allDistMargin(node, m, M, 1)
+	if xMargin < yMargin {
+		tr.sortNode(node, 0)
+	}
+}
+
+type itemsByDim[T comparable] struct {
+	items []T
+	bbox  func(item T) (min, max [2]float64)
+	axis  int
+}
+
+func (arr *itemsByDim[T]) Len() int { return len(arr.items) }
+func (arr *itemsByDim[T]) Less(i, j int) bool {
+	a, _ := arr.bbox(arr.items[i])
+	b, _ := arr.bbox(arr.items[j])
+	return a[arr.axis] < b[arr.axis]
+}
+func (arr *itemsByDim[T]) Swap(i, j int) {
+	arr.items[i], arr.items[j] = arr.items[j], arr.items[i]
+}
+
+type tnodesByDim[T comparable] struct {
+	nodes []*tnode[T]
+	axis  int
+}
+
+func (arr *tnodesByDim[T]) Len() int { return len(arr.nodes) }
+func (arr *tnodesByDim[T]) Less(i, j int) bool {
+	return arr.nodes[i].min[arr.axis] < arr.nodes[j].min[arr.axis]
+}
+func (arr *tnodesByDim[T]) Swap(i, j int) {
+	arr.nodes[i], arr.nodes[j] = arr.nodes[j], arr.nodes[i]
+}
+
+func (tr *Tree[T]) sortNode(node *tnode[T], axis int) {
+	if node.leaf {
+		sort.Sort(&itemsByDim[T]{items: node.items, bbox: tr.bbox, axis: axis})
+	} else {
+		sort.Sort(&tnodesByDim[T]{nodes: node.children, axis: axis})
+	}
+}
+
+// childBBox returns the bounding box of the node's i-th child
+func (tr *Tree[T]) childBBox(node *tnode[T], i int) (min, max [2]float64) {
+	if node.leaf {
+		return tr.bbox(node.items[i])
+	}
+	return node.children[i].min, node.children[i].max
+}
+
+// allDistMargin sorts the node's children based on the their margin for
+// the specified axis
+func (tr *Tree[T]) allDistMargin(node *tnode[T], m, M int, axis int) float64 {
+	tr.sortNode(node, axis)
+	leftMin, leftMax := tr.distBBox(node, 0, m)
+	rightMin, rightMax := tr.distBBox(node, M-m, M)
+	left := tnode[T]{min: leftMin, max: leftMax}
+	right := tnode[T]{min: rightMin, max: rightMax}
+	margin := boxMargin(leftMin, leftMax) + boxMargin(rightMin, rightMax)
+
+	for i := m; i < M-m; i++ {
+		left.extend(tr.childBBox(node, i))
+		margin += boxMargin(left.min, left.max)
+	}
+	for i := M - m - 1; i >= m; i-- {
+		right.extend(tr.childBBox(node, i))
+		margin += boxMargin(right.min, right.max)
+	}
+	return margin
+}
+
+func (tr *Tree[T]) condense(path []*tnode[T]) {
+	// go through the path, removing empty nodes and updating bboxes
+	for i := len(path) - 1; i >= 0; i-- {
+		if path[i].len() == 0 {
+			if i > 0 {
+				siblings := path[i-1].children
+				for j := range siblings {
+					if siblings[j] == path[i] {
+						copy(siblings[j:], siblings[j+1:])
+						siblings[len(siblings)-1] = nil
+						path[i-1].children = siblings[:len(siblings)-1]
+						break
+					}
+				}
+			} else {
+				tr.Clear()
+			}
+		} else {
+			tr.calcBBox(path[i])
+		}
+	}
+}
+
+func (tr *Tree[T]) calcBBox(node *tnode[T]) {
+	node.min, node.max = tr.distBBox(node, 0, node.len())
+}
+
+// distBBox returns the bounding box of the node's children from k to p-1
+func (tr *Tree[T]) distBBox(node *tnode[T], k, p int) (min, max [2]float64) {
+	box := newTNode[T](true)
+	for i := k; i < p; i++ {
+		box.extend(tr.childBBox(node, i))
+	}
+	return box.min, box.max
+}
+
+func (tr *Tree[T]) compare(a, b T, axis int) float64 {
+	amin, _ := tr.bbox(a)
+	bmin, _ := tr.bbox(b)
+	return amin[axis] - bmin[axis]
+}
+
+func (tr *Tree[T]) quickselect(arr []T, k, left, right int, axis int) {
+	for right > left {
+		if right-left > 600 {
+			n := float64(right - left + 1)
+			m := float64(k - left + 1)
+			z := math.Log(n)
+			s := 0.5 * math.Exp(2.0*z/3.0)
+			var d float64
+			if m-n/2 < 0 {
+				d = -1
+			} else {
+				d = 1
+			}
+			sd := 0.5 * math.Sqrt(z*s*(n-s)/n) * d
+			newLeft := mathMax(float64(left), math.Floor(float64(k)-m*s/n+sd))
+			newRight := mathMin(float64(right), math.Floor(float64(k)+(n-m)*s/n+sd))
+			tr.quickselect(arr, k, int(newLeft), int(newRight), axis)
+		}
+
+		t := arr[k]
+		i := left
+		j := right
+
+		arr[left], arr[k] = arr[k], arr[left]
+		if tr.compare(arr[right], t, axis) > 0 {
+			arr[left], arr[right] = arr[right], arr[left]
+		}
+
+		for i < j {
+			arr[i], arr[j] = arr[j], arr[i]
+			i++
+			j--
+			for tr.compare(arr[i], t, axis) < 0 {
+				i++
+			}
+			for tr.compare(arr[j], t, axis) > 0 {
+				j--
+			}
+		}
+
+		if tr.compare(arr[left], t, axis) == 0 {
+			arr[left], arr[j] = arr[j], arr[left]
+		} else {
+			j++
+			arr[j], arr[right] = arr[right], arr[j]
+		}
+
+		if j <= k {
+			left = j + 1
+		}
+		if k <= j {
+			right = j - 1
+		}
+	}
+}
+
+func (tr *Tree[T]) multiSelect(arr []T, left, right, n int, axis int) {
+	stack := []int{left, right}
+
+	for len(stack) > 0 {
+		right = stack[len(stack)-1]
+		left = stack[len(stack)-2]
+		stack = stack[:len(stack)-2]
+
+		if right-left <= n {
+			continue
+		}
+
+		mid := left + int(math.Ceil(float64(right-left)/float64(n)/2.0)*float64(n))
+		tr.quickselect(arr, mid, left, right, axis)
+
+		stack = append(stack, left, mid, mid, right)
+	}
+}
+
+func boxIntersects(amin, amax, bmin, bmax [2]float64) bool {
+	return bmin[0] <= amax[0] && bmax[0] >= amin[0] &&
+		bmin[1] <= amax[1] && bmax[1] >= amin[1]
+}
+
+func boxContains(amin, amax, bmin, bmax [2]float64) bool {
+	return amin[0] <= bmin[0] && bmax[0] <= amax[0] &&
+		amin[1] <= bmin[1] && bmax[1] <= amax[1]
+}
+
+func boxArea(min, max [2]float64) float64 {
+	return (max[0] - min[0]) * (max[1] - min[1])
+}
+
+func boxMargin(min, max [2]float64) float64 {
+	return (max[0] - min[0]) + (max[1] - min[1])
+}
+
+func enlargedArea(amin, amax, bmin, bmax [2]float64) float64 {
+	return (mathMax(bmax[0], amax[0]) - mathMin(bmin[0], amin[0])) *
+		(mathMax(bmax[1], amax[1]) - mathMin(bmin[1], amin[1]))
+}
+
+func intersectionArea(amin, amax, bmin, bmax [2]float64) float64 {
+	minX := mathMax(amin[0], bmin[0])
+	minY := mathMax(amin[1], bmin[1])
+	maxX := mathMin(amax[0], bmax[0])
+	maxY := mathMin(amax[1], bmax[1])
+	return mathMax(0, maxX-minX) * mathMax(0, maxY-minY)
+}
@@ -0,0 +1,178 @@
+package rbush_test
+
+import (
+	"math/rand"
+	"sort"
+	"testing"
+
+	"github.com/wsw0108/concaveman-go/internal/testutil"
+	"github.com/wsw0108/concaveman-go/rbush"
+)
+
+// searchIDs returns the sorted ids of the boxes in the tree overlapping (min, max)
+func searchIDs(tree *rbush.Tree[testutil.Box], min, max [2]float64) []int {
+	var ids []int
+	tree.Search(min, max, func(b testutil.Box) bool {
+		ids = append(ids, b.ID)
+		return true
+	})
+	sort.Ints(ids)
+	return ids
+}
+
+func TestTreeSearch(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	boxes := testutil.Boxes(r, 2000)
+
+	tests := []struct {
+		name string
+		fill func(tree *rbush.Tree[testutil.Box])
+	}{
+		{"insert", func(tree *rbush.Tree[testutil.Box]) {
+			for _, b := range boxes {
+				tree.Insert(b)
+			}
+		}},
+		{"load", func(tree *rbush.Tree[testutil.Box]) {
+			tree.Load(append([]testutil.Box(nil), boxes...))
+		}},
+		{"load in parts", func(tree *rbush.Tree[testutil.Box]) {
+			tree.Load(append([]testutil.Box(nil), boxes[:1500]...))
+			tree.Load(append([]testutil.Box(nil), boxes[1500:1600]...))
+			tree.Load(append([]testutil.Box(nil), boxes[1600:1601]...))
+			tree.Load(append([]testutil.Box(nil), boxes[1601:]...))
+		}},
+	}
+	for _, tt := range tests {
+		tree := rbush.NewTree(9, testutil.Box.Rect)
+		tt.fill(tree)
+		for i := 0; i < 50; i++ {
+			x, y := r.Float64()*100, r.Float64()*100
+			min, max := [2]float64{x, y}, [2]float64{x + 10, y + 10}
+			if got, want := searchIDs(tree, min, max), testutil.Overlapping(boxes, nil, min, max); !testutil.EqualIDs(got, want) {
+				t.Errorf("%s: search %v %v found %d boxes, want %d", tt.name, min, max, len(got), len(want))
+			}
+		}
+	}
+
+	var n int
+	tree := rbush.NewTree(9, testutil.Box.Rect)
+	tree.Load(append([]testutil.Box(nil), boxes...))
+	done := tree.Search([2]float64{0, 0}, [2]float64{100, 100}, func(testutil.Box) bool {
+		n++
+		return n < 10
+	})
+	if done || n != 10 {
+		t.Errorf("stop: done = %v after %d boxes", done, n)
+	}
+}
+
+func TestTreeRemove(t *testing.T) {
+	r := rand.New(rand.NewSource(2))
+	boxes := testutil.Boxes(r, 1000)
+	tree := rbush.NewTree(6, testutil.Box.Rect)
+	tree.Load(append([]testutil.Box(nil), boxes...))
+
+	// removing an item that is not in the tree is a no-op
+	tree.Remove(testutil.Box{ID: -1, Min: [2]float64{1, 1}, Max: [2]float64{2, 2}})
+
+	r.Shuffle(len(boxes), func(i, j int) { boxes[i], boxes[j] = boxes[j], boxes[i] })
+	all := [2][2]float64{{-1, -1}, {200, 200}}
+	for len(boxes) > 0 {
+		tree.Remove(boxes[len(boxes)-1])
+		boxes = boxes[:len(boxes)-1]
+		if len(boxes)%100 == 0 {
+			if got, want := searchIDs(tree, all[0], all[1]), testutil.Overlapping(boxes, nil, all[0], all[1]); !testutil.EqualIDs(got, want) {
+				t.Fatalf("%d left: found %d boxes", len(want), len(got))
+			}
+		}
+	}
+
+	// the emptied tree can be reused
+	tree.Insert(testutil.Box{ID: 7, Max: [2]float64{1, 1}})
+	if got := searchIDs(tree, all[0], all[1]); !testutil.EqualIDs(got, []int{7}) {
+		t.Errorf("after reuse: found %v", got)
+	}
+}
+
+func TestTreeValueItems(t *testing.T) {
+	tree := rbush.NewTree(4, func(p point) (min, max [2]float64) { return p, p })
+	// equal values are separate items
+	for i := 0; i < 3; i++ {
+		tree.Insert(point{1, 1})
+	}
+	tree.Insert(point{2, 2})
+	tree.Remove(point{1, 1})
+
+	var n int
+	tree.Search([2]float64{0, 0}, [2]float64{1, 1}, func(point) bool {
+		n++
+		return true
+	})
+	if n != 2 {
+		t.Errorf("found %d points, want 2", n)
+	}
+}
+
+func TestTreeNearest(t *testing.T) {
+	items := randomPoints(1000)
+	points := make([]point, len(items))
+	for i, item := range items {
+		points[i] = item.(point)
+	}
+	tree := rbush.NewTree(9, func(p point) (min, max [2]float64) { return p, p })
+	tree.Load(points)
+
+	q := [2]float64{30, 60}
+	got := tree.Nearest(q, 20, 0, nil)
+	want := bruteNearest(items, q, 20, 0)
+	if len(got) != len(want) {
+		t.Fatalf("got %d points, want %d", len(got), len(want))
+	}
+	for i, p := range got {
+		if d := dist(p, q); d != want[i] {
+			t.Errorf("point %d at distance %v, want %v", i, d, want[i])
+		}
+	}
+
+	// the same order as RBush
+	rtree := rbush.New(9)
+	rtree.Load(append([]rbush.Item(nil), items...))
+	for i, item := range rtree.Nearest(q, 20, 0, nil) {
+		if item.(point) != got[i] {
+			t.Errorf("point %d is %v, RBush found %v", i, got[i], item)
+		}
+	}
+}
+
+func benchmarkPoints(n int) []point {
+	return testutil.Points[point](rand.New(rand.NewSource(3)), n, 1)
+}
+
+func BenchmarkRBushLoadSearch(b *testing.B) {
+	points := benchmarkPoints(100000)
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		items := make([]rbush.Item, len(points))
+		for j, p := range points {
+			items[j] = p
+		}
+		tree := rbush.New(16)
+		tree.Load(items)
+		for _, p := range points[:1000] {
+			tree.Search(testutil.Box{Min: p, Max: [2]float64{p[0] + 0.01, p[1] + 0.01}}, func(rbush.Item) bool { return true })
+		}
+	}
+}
+
+func BenchmarkTreeLoadSearch(b *testing.B) {
+	points := benchmarkPoints(100000)
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		tree := rbush.NewTree(16, func(p point) (min, max [2]float64) { return p, p })
+		tree.Load(append([]point(nil), points...))
+		for _, p := range points[:1000] {
+			tree.Search(p, [2]float64{p[0] + 0.01, p[1] + 0.01}, func(point) bool { return true })
+		}
+	}
+}
@@ -0,0 +1,244 @@
+// Package render draws what concaveman did for a point set: the input points,
+// the starting convex hull, the quadrilateral used to cull points for it, and
+// the final concave hull. Scenes can be written as SVG or rasterized to PNG.
+package render
+
+import (
+	"context"
+	"fmt"
+	"image"
+	"image/color"
+	"image/png"
+	"io"
+	"math"
+	"strings"
+
+	"github.com/wsw0108/concaveman-go"
+)
+
+// Scene holds the geometries to draw. Rings are closed.
+type Scene struct {
+	Points     []concaveman.Point
+	ConvexHull []concaveman.Point
+	CullQuad   []concaveman.Point
+	Hull       concaveman.Polygon
+}
+
+// Compute runs concaveman on the points with the given options and returns
+// the scene to draw. It returns one of the errors documented on
+// concaveman.ConcavemanE for degenerate input.
+func Compute(points []concaveman.Point, opt concaveman.Options) (*Scene, error) {
+	h, err := concaveman.NewHullOptions(points, opt)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := h.RefineContext(context.Background(), opt); err != nil {
+		return nil, err
+	}
+	return &Scene{
+		Points:     points,
+		ConvexHull: h.ConvexHull(),
+		CullQuad:   h.CullingQuad(),
+		Hull:       h.Polygon(opt.MinHoleArea),
+	}, nil
+}
+
+var (
+	pointColor  = color.RGBA{0x55, 0x55, 0x55, 0xff}
+	convexColor = color.RGBA{0x1f, 0x77, 0xb4, 0xff}
+	cullColor   = color.RGBA{0xff, 0x7f, 0x0e, 0xff}
+	hullColor   = color.RGBA{0xd6, 0x27, 0x28, 0xff}
+)
+
+const margin = 10
+
+// MinSize and MaxSize bound the width and height of an image in pixels.
+const (
+	MinSize = 4 * margin
+	MaxSize = 16384
+)
+
+// ErrSize is returned when drawing an image smaller than MinSize or larger
+// than MaxSize.
+var ErrSize = fmt.Errorf("render: image size must be between %d and %d pixels", MinSize, MaxSize)
+
+func checkSize(width, height int) error {
+	if width < MinSize || width > MaxSize || height < MinSize || height > MaxSize {
+		return ErrSize
+	}
+	return nil
+}
+
+// transform maps scene coordinates to image pixels, flipping the y axis
+type transform struct {
+	minX, maxY float64
+	scale      float64
+	offX, offY float64
+}
+
+func newTransform(s *Scene, width, height int) transform {
+	minX, minY := math.Inf(+1), math.Inf(+1)
+	maxX, maxY := math.Inf(-1), math.Inf(-1)
+	for _, p := range s.Points {
+		minX = math.Min(minX, p[0])
+		minY = math.Min(minY, p[1])
+		maxX = math.Max(maxX, p[0])
+		maxY = math.Max(maxY, p[1])
+	}
+	if len(s.Points) == 0 {
+		minX, minY, maxX, maxY = 0, 0, 1, 1
+	}
+	w := float64(width - 2*margin)
+	h := float64(height - 2*margin)
+	dx := maxX - minX
+	dy := maxY - minY
+	scale := math.Min(w/dx, h/dy)
+	if dx == 0 && dy == 0 {
+		scale = 1
+	} else if dx == 0 {
+		scale = h / dy
+	} else if dy == 0 {
+		scale = w / dx
+	}
+	// center the drawing
+	return transform{
+		minX:  minX,
+		maxY:  maxY,
+		scale: scale,
+		offX:  margin + (w-dx*scale)/2,
+		offY:  margin + (h-dy*scale)/2,
+	}
+}
+
+func (t transform) apply(p concaveman.Point) (float64, float64) {
+	return t.offX + (p[0]-t.minX)*t.scale, t.offY + (t.maxY-p[1])*t.scale
+}
+
+// SVG writes the scene as an SVG document of the given size in pixels.
+func SVG(w io.Writer, s *Scene, width, height int) error {
+	if err := checkSize(width, height); err != nil {
+		return err
+	}
+	t := newTransform(s, width, height)
+	var b strings.Builder
+	fmt.Fprintf(&b, "<svg xmlns=\"http://www.w3.org/2000/svg\" width=\"%d\" height=\"%d\" viewBox=\"0 0 %d %d\">\n", width, height, width, height)
+	b.WriteString("<rect width=\"100%\" height=\"100%\" fill=\"white\"/>\n")
+
+	b.WriteString("<g fill=\"" + hex(pointColor) + "\">\n")
+	for _, p := range s.Points {
+		x, y := t.apply(p)
+		fmt.Fprintf(&b, "<circle cx=\"%.2f\" cy=\"%.2f\" r=\"1.5\"/>\n", x, y)
+	}
+	b.WriteString("</g>\n")
+
+	writePath(&b, t, [][]concaveman.Point{s.CullQuad}, cullColor, "stroke-dasharray=\"4 2\" ")
+	writePath(&b, t, [][]concaveman.Point{s.ConvexHull}, convexColor, "")
+	writePath(&b, t, s.Hull, hullColor, "stroke-width=\"1.5\" ")
+
+	b.WriteString("</svg>\n")
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+func writePath(b *strings.Builder, t transform, rings [][]concaveman.Point, c color.RGBA, attrs string) {
+	var d strings.Builder
+	for _, ring := range rings {
+		for i, p := range ring {
+			x, y := t.apply(p)
+			if i == 0 {
+				fmt.Fprintf(&d, "M%.2f %.2f", x, y)
+			} else {
+				fmt.Fprintf(&d, "L%.2f %.2f", x, y)
+			}
+		}
+		if len(ring) > 0 {
+			d.WriteString("Z")
+		}
+	}
+	if d.Len() == 0 {
+		return
+	}
+	fmt.Fprintf(b, "<path d=\"%s\" fill=\"none\" fill-rule=\"evenodd\" stroke=\"%s\" %s/>\n", d.String(), hex(c), attrs)
+}
+
+func hex(c color.RGBA) string {
+	return fmt.Sprintf("#%02x%02x%02x", c.R, c.G, c.B)
+}
+
+// Image rasterizes the scene into an image of the given size in pixels, which
+// should be within MinSize and MaxSize.
+func Image(s *Scene, width, height int) *image.RGBA {
+	t := newTransform(s, width, height)
+	img := image.NewRGBA(image.Rect(0, 0, width, height))
+	for i := range img.Pix {
+		img.Pix[i] = 0xff
+	}
+
+	for _, p := range s.Points {
+		x, y := t.apply(p)
+		cx, cy := int(math.Round(x)), int(math.Round(y))
+		for dy := -1; dy <= 1; dy++ {
+			for dx := -1; dx <= 1; dx++ {
+				img.SetRGBA(cx+dx, cy+dy, pointColor)
+			}
+		}
+	}
+	drawRing(img, t, s.CullQuad, cullColor)
+	drawRing(img, t, s.ConvexHull, convexColor)
+	for _, ring := range s.Hull {
+		drawRing(img, t, ring, hullColor)
+	}
+	return img
+}
+
+// PNG writes the scene rasterized by Image as a PNG image.
+func PNG(w io.Writer, s *Scene, width, height int) error {
+	if err := checkSize(width, height); err != nil {
+		return err
+	}
+	return png.Encode(w, Image(s, width, height))
+}
+
+func drawRing(img *image.RGBA, t transform, ring []concaveman.Point, c color.RGBA) {
+	for i := 0; i+1 < len(ring); i++ {
+		x0, y0 := t.apply(ring[i])
+		x1, y1 := t.apply(ring[i+1])
+		drawLine(img, int(math.Round(x0)), int(math.Round(y0)), int(math.Round(x1)), int(math.Round(y1)), c)
+	}
+}
+
+// Bresenham's line algorithm
+func drawLine(img *image.RGBA, x0, y0, x1, y1 int, c color.RGBA) {
+	dx := abs(x1 - x0)
+	dy := -abs(y1 - y0)
+	sx, sy := 1, 1
+	if x0 > x1 {
+		sx = -1
+	}
+	if y0 > y1 {
+		sy = -1
+	}
+	e := dx + dy
+	for {
+		img.SetRGBA(x0, y0, c)
+		if x0 == x1 && y0 == y1 {
+			return
+		}
+		e2 := 2 * e
+		if e2 >= dy {
+			e += dy
+			x0 += sx
+		}
+		if e2 <= dx {
+			e += dx
+			y0 += sy
+		}
+	}
+}
+
+func abs(x int) int {
+	if x < 0 {
+		return -x
+	}
+	return x
+}
@@ -0,0 +1,101 @@
+package render_test
+
+import (
+	"bytes"
+	"errors"
+	"image/png"
+	"strings"
+	"testing"
+
+	"github.com/wsw0108/concaveman-go"
+	"github.com/wsw0108/concaveman-go/render"
+)
+
+var points = []concaveman.Point{
+	{0, 0},
+	{2, 0},
+	{1, 2},
+	{1, 1},
+	{1, 0.5},
+}
+
+func TestCompute(t *testing.T) {
+	s, err := render.Compute(points, concaveman.Options{Concavity: 2})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(s.ConvexHull) != 4 || len(s.CullQuad) != 5 || len(s.Hull) != 1 {
+		t.Errorf("Compute() = %+v", s)
+	}
+
+	_, err = render.Compute(points[:2], concaveman.Options{})
+	if !errors.Is(err, concaveman.ErrTooFewPoints) {
+		t.Errorf("Compute() error = %v", err)
+	}
+}
+
+// flip is a projection mirroring the x axis
+type flip struct{}
+
+func (flip) Forward(x, y float64) (float64, float64) { return -x, y }
+
+func TestComputeOptions(t *testing.T) {
+	plain, _ := render.Compute(points, concaveman.Options{Concavity: 2})
+	s, err := render.Compute(points, concaveman.Options{Concavity: 2, Projection: flip{}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	// the same rings, in input coordinates, but traversed the other way
+	if len(s.ConvexHull) != len(plain.ConvexHull) || len(s.Hull[0]) != len(plain.Hull[0]) {
+		t.Fatalf("Compute() = %+v, want %+v", s, plain)
+	}
+	for _, p := range s.ConvexHull {
+		if p[0] < 0 {
+			t.Errorf("Compute() convex hull has projected point %v", p)
+		}
+	}
+}
+
+func TestSize(t *testing.T) {
+	s, _ := render.Compute(points, concaveman.Options{Concavity: 2})
+	for _, size := range [][2]int{{render.MinSize - 1, 100}, {100, 0}, {render.MaxSize + 1, 100}} {
+		if err := render.SVG(&bytes.Buffer{}, s, size[0], size[1]); err != render.ErrSize {
+			t.Errorf("SVG() with size %v: error = %v", size, err)
+		}
+		if err := render.PNG(&bytes.Buffer{}, s, size[0], size[1]); err != render.ErrSize {
+			t.Errorf("PNG() with size %v: error = %v", size, err)
+		}
+	}
+}
+
+func TestSVG(t *testing.T) {
+	s, _ := render.Compute(points, concaveman.Options{Concavity: 2})
+	var buf bytes.Buffer
+	if err := render.SVG(&buf, s, 200, 100); err != nil {
+		t.Fatal(err)
+	}
+	out := buf.String()
+	if !strings.HasPrefix(out, "<svg") || strings.Count(out, "<circle") != len(points) || strings.Count(out, "<path") != 3 {
+		t.Errorf("SVG() = %s", out)
+	}
+}
+
+func TestPNG(t *testing.T) {
+	s, _ := render.Compute(points, concaveman.Options{Concavity: 2})
+	var buf bytes.Buffer
+	if err := render.PNG(&buf, s, 200, 100); err != nil {
+		t.Fatal(err)
+	}
+	img, err := png.Decode(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if b := img.Bounds(); b.Dx() != 200 || b.Dy() != 100 {
+		t.Errorf("PNG() size = %v", b)
+	}
+	// the bottom left point (0, 0) lands at the margin
+	r, g, b, _ := img.At(60, 90).RGBA()
+	if r == 0xffff && g == 0xffff && b == 0xffff {
+		t.Error("PNG() did not draw the point at the origin")
+	}
+}
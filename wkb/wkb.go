@@ -0,0 +1,236 @@
+// Package wkb reads input points for concaveman from Well-Known Binary, as
+// well as PostGIS extended WKB, and writes the resulting hulls as WKB
+// polygons.
+package wkb
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math"
+
+	"github.com/wsw0108/concaveman-go"
+)
+
+// geometry type codes
+const (
+	wkbPoint              = 1
+	wkbLineString         = 2
+	wkbPolygon            = 3
+	wkbMultiPoint         = 4
+	wkbMultiLineString    = 5
+	wkbMultiPolygon       = 6
+	wkbGeometryCollection = 7
+)
+
+// EWKB flags
+const (
+	ewkbZ    = 0x80000000
+	ewkbM    = 0x40000000
+	ewkbSRID = 0x20000000
+)
+
+var (
+	// ErrUnsupportedType is returned for geometries of an unknown type.
+	ErrUnsupportedType = errors.New("wkb: unsupported geometry type")
+	// ErrTruncated is returned when the data ends in the middle of a geometry.
+	ErrTruncated = errors.New("wkb: truncated data")
+)
+
+// Decode parses a WKB or EWKB geometry of any of the standard types, including
+// ISO and EWKB variants with Z and M coordinates, and returns all of its
+// vertices along with its SRID, which is zero when absent. Coordinates beyond
+// x and y are dropped.
+func Decode(data []byte) ([]concaveman.Point, int, error) {
+	d := &decoder{data: data}
+	var points []concaveman.Point
+	srid, err := d.geometry(&points)
+	if err != nil {
+		return nil, 0, err
+	}
+	if d.pos != len(d.data) {
+		return nil, 0, fmt.Errorf("wkb: %d trailing bytes", len(d.data)-d.pos)
+	}
+	return points, srid, nil
+}
+
+type decoder struct {
+	data  []byte
+	pos   int
+	order binary.ByteOrder
+}
+
+func (d *decoder) byteOrder() error {
+	if d.pos >= len(d.data) {
+		return ErrTruncated
+	}
+	switch d.data[d.pos] {
+	case 0:
+		d.order = binary.BigEndian
+	case 1:
+		d.order = binary.LittleEndian
+	default:
+		return fmt.Errorf("wkb: invalid byte order %d", d.data[d.pos])
+	}
+	d.pos++
+	return nil
+}
+
+func (d *decoder) uint32() (uint32, error) {
+	if d.pos+4 > len(d.data) {
+		return 0, ErrTruncated
+	}
+	v := d.order.Uint32(d.data[d.pos:])
+	d.pos += 4
+	return v, nil
+}
+
+func (d *decoder) float64() (float64, error) {
+	if d.pos+8 > len(d.data) {
+		return 0, ErrTruncated
+	}
+	v := math.Float64frombits(d.order.Uint64(d.data[d.pos:]))
+	d.pos += 8
+	return v, nil
+}
+
+// count reads a number of elements, each at least size bytes long
+func (d *decoder) count(size int) (int, error) {
+	n, err := d.uint32()
+	if err != nil {
+		return 0, err
+	}
+	if uint64(n)*uint64(size) > uint64(len(d.data)-d.pos) {
+		return 0, ErrTruncated
+	}
+	return int(n), nil
+}
+
+func (d *decoder) geometry(points *[]concaveman.Point) (int, error) {
+	if err := d.byteOrder(); err != nil {
+		return 0, err
+	}
+	typ, err := d.uint32()
+	if err != nil {
+		return 0, err
+	}
+
+	dims := 2
+	if typ&ewkbZ != 0 {
+		dims++
+	}
+	if typ&ewkbM != 0 {
+		dims++
+	}
+	srid := 0
+	if typ&ewkbSRID != 0 {
+		v, err := d.uint32()
+		if err != nil {
+			return 0, err
+		}
+		srid = int(v)
+	}
+	typ &^= ewkbZ | ewkbM | ewkbSRID
+
+	// ISO WKB encodes Z and M as 1000, 2000 or 3000 added to the type
+	switch typ / 1000 {
+	case 0:
+	case 1, 2:
+		dims = 3
+	case 3:
+		dims = 4
+	default:
+		return 0, fmt.Errorf("%w %d", ErrUnsupportedType, typ)
+	}
+	typ %= 1000
+
+	switch typ {
+	case wkbPoint:
+		err = d.positions(points, 1, dims)
+	case wkbLineString:
+		err = d.sequence(points, dims)
+	case wkbPolygon:
+		var rings int
+		rings, err = d.count(4)
+		for i := 0; i < rings && err == nil; i++ {
+			err = d.sequence(points, dims)
+		}
+	case wkbMultiPoint, wkbMultiLineString, wkbMultiPolygon, wkbGeometryCollection:
+		var n int
+		n, err = d.count(5)
+		for i := 0; i < n && err == nil; i++ {
+			_, err = d.geometry(points)
+		}
+	default:
+		return 0, fmt.Errorf("%w %d", ErrUnsupportedType, typ)
+	}
+	return srid, err
+}
+
+func (d *decoder) sequence(points *[]concaveman.Point, dims int) error {
+	n, err := d.count(8 * dims)
+	if err != nil {
+		return err
+	}
+	return d.positions(points, n, dims)
+}
+
+func (d *decoder) positions(points *[]concaveman.Point, n, dims int) error {
+	for i := 0; i < n; i++ {
+		var p concaveman.Point
+		for j := 0; j < dims; j++ {
+			v, err := d.float64()
+			if err != nil {
+				return err
+			}
+			if j < 2 {
+				p[j] = v
+			}
+		}
+		// an empty point is written with NaN coordinates
+		if i == 0 && n == 1 && math.IsNaN(p[0]) && math.IsNaN(p[1]) {
+			continue
+		}
+		*points = append(*points, p)
+	}
+	return nil
+}
+
+// Polygon encodes a polygon, such as a hull wrapped in concaveman.Polygon, as
+// little-endian WKB. A non-zero srid produces PostGIS EWKB carrying the SRID.
+func Polygon(poly concaveman.Polygon, srid int) []byte {
+	buf := header(nil, wkbPolygon, srid)
+	return appendPolygon(buf, poly)
+}
+
+// MultiPolygon encodes polygons as a WKB MultiPolygon, see Polygon.
+func MultiPolygon(polys []concaveman.Polygon, srid int) []byte {
+	buf := header(nil, wkbMultiPolygon, srid)
+	buf = binary.LittleEndian.AppendUint32(buf, uint32(len(polys)))
+	for _, poly := range polys {
+		buf = header(buf, wkbPolygon, 0)
+		buf = appendPolygon(buf, poly)
+	}
+	return buf
+}
+
+func header(buf []byte, typ uint32, srid int) []byte {
+	buf = append(buf, 1)
+	if srid != 0 {
+		buf = binary.LittleEndian.AppendUint32(buf, typ|ewkbSRID)
+		return binary.LittleEndian.AppendUint32(buf, uint32(srid))
+	}
+	return binary.LittleEndian.AppendUint32(buf, typ)
+}
+
+func appendPolygon(buf []byte, poly concaveman.Polygon) []byte {
+	buf = binary.LittleEndian.AppendUint32(buf, uint32(len(poly)))
+	for _, ring := range poly {
+		buf = binary.LittleEndian.AppendUint32(buf, uint32(len(ring)))
+		for _, p := range ring {
+			buf = binary.LittleEndian.AppendUint64(buf, math.Float64bits(p[0]))
+			buf = binary.LittleEndian.AppendUint64(buf, math.Float64bits(p[1]))
+		}
+	}
+	return buf
+}
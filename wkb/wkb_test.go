@@ -0,0 +1,123 @@
+package wkb_test
+
+import (
+	"encoding/hex"
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/wsw0108/concaveman-go"
+	"github.com/wsw0108/concaveman-go/wkb"
+)
+
+func TestDecode(t *testing.T) {
+	tests := []struct {
+		name string
+		hex  string
+		want []concaveman.Point
+		srid int
+	}{
+		{
+			name: "POINT/ndr",
+			hex:  "0101000000000000000000f03f0000000000000040",
+			want: []concaveman.Point{{1, 2}},
+		},
+		{
+			name: "POINT/xdr",
+			hex:  "00000000013ff00000000000004000000000000000",
+			want: []concaveman.Point{{1, 2}},
+		},
+		{
+			name: "POINT/ewkb",
+			hex:  "0101000020e6100000000000000000f03f0000000000000040",
+			want: []concaveman.Point{{1, 2}},
+			srid: 4326,
+		},
+		{
+			name: "POINT Z/iso",
+			hex:  "01e9030000000000000000f03f00000000000000400000000000000840",
+			want: []concaveman.Point{{1, 2}},
+		},
+		{
+			name: "POINT EMPTY",
+			hex:  "0101000000000000000000f87f000000000000f87f",
+			want: nil,
+		},
+		{
+			name: "MULTIPOINT",
+			hex: "010400000002000000" +
+				"0101000000000000000000f03f0000000000000040" +
+				"010100000000000000000008400000000000001040",
+			want: []concaveman.Point{{1, 2}, {3, 4}},
+		},
+		{
+			name: "LINESTRING Z/ewkb",
+			hex:  "01020000800200000000000000000000000000000000000000000000000000f03f000000000000f03f000000000000f03f000000000000f03f",
+			want: []concaveman.Point{{0, 0}, {1, 1}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data, _ := hex.DecodeString(tt.hex)
+			got, srid, err := wkb.Decode(data)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(got, tt.want) || srid != tt.srid {
+				t.Errorf("Decode() = %v, %d, want %v, %d", got, srid, tt.want, tt.srid)
+			}
+		})
+	}
+
+	data, _ := hex.DecodeString("0108000000")
+	if _, _, err := wkb.Decode(data); !errors.Is(err, wkb.ErrUnsupportedType) {
+		t.Errorf("Decode() error = %v", err)
+	}
+	data, _ = hex.DecodeString("0101000000000000000000f03f00000000")
+	if _, _, err := wkb.Decode(data); !errors.Is(err, wkb.ErrTruncated) {
+		t.Errorf("Decode() error = %v", err)
+	}
+	data, _ = hex.DecodeString("0102000000ffffffff")
+	if _, _, err := wkb.Decode(data); !errors.Is(err, wkb.ErrTruncated) {
+		t.Errorf("Decode() error = %v", err)
+	}
+}
+
+func TestPolygon(t *testing.T) {
+	poly := concaveman.Polygon{
+		{{0, 0}, {10, 0}, {0, 10}, {0, 0}},
+		{{1, 1}, {1, 2}, {2, 1}, {1, 1}},
+	}
+	var all []concaveman.Point
+	for _, ring := range poly {
+		all = append(all, ring...)
+	}
+
+	data := wkb.Polygon(poly[:1], 0)
+	want := "01030000000100000004000000" +
+		"00000000000000000000000000000000" +
+		"00000000000024400000000000000000" +
+		"00000000000000000000000000002440" +
+		"00000000000000000000000000000000"
+	if got := hex.EncodeToString(data); got != want {
+		t.Errorf("Polygon() = %s, want %s", got, want)
+	}
+
+	for _, srid := range []int{0, 3857} {
+		points, gotSRID, err := wkb.Decode(wkb.Polygon(poly, srid))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !reflect.DeepEqual(points, all) || gotSRID != srid {
+			t.Errorf("Decode(Polygon()) = %v, %d", points, gotSRID)
+		}
+
+		points, gotSRID, err = wkb.Decode(wkb.MultiPolygon([]concaveman.Polygon{poly[:1], poly[1:]}, srid))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !reflect.DeepEqual(points, all) || gotSRID != srid {
+			t.Errorf("Decode(MultiPolygon()) = %v, %d", points, gotSRID)
+		}
+	}
+}
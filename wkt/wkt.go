@@ -0,0 +1,266 @@
+// Package wkt reads input points for concaveman from Well-Known Text and
+// writes the resulting hulls as WKT polygons.
+package wkt
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+
+	"github.com/wsw0108/concaveman-go"
+)
+
+// ErrUnsupportedType is returned for geometries of an unknown type.
+var ErrUnsupportedType = errors.New("wkt: unsupported geometry type")
+
+// Decode parses a WKT geometry (POINT, MULTIPOINT, LINESTRING,
+// MULTILINESTRING, POLYGON, MULTIPOLYGON or GEOMETRYCOLLECTION, optionally
+// with Z, M or ZM coordinates and an EWKT "SRID=n;" prefix) and returns all of
+// its vertices. Coordinates beyond x and y are dropped.
+func Decode(s string) ([]concaveman.Point, error) {
+	if strings.HasPrefix(strings.ToUpper(s), "SRID=") {
+		i := strings.IndexByte(s, ';')
+		if i < 0 {
+			return nil, errors.New("wkt: SRID without geometry")
+		}
+		s = s[i+1:]
+	}
+	p := &parser{s: s}
+	var points []concaveman.Point
+	if err := p.geometry(&points); err != nil {
+		return nil, err
+	}
+	p.skipSpace()
+	if p.pos != len(p.s) {
+		return nil, p.errorf("unexpected %q", p.s[p.pos:])
+	}
+	return points, nil
+}
+
+type parser struct {
+	s   string
+	pos int
+	// number of coordinates per position
+	dims int
+}
+
+func (p *parser) errorf(format string, args ...interface{}) error {
+	return fmt.Errorf("wkt: at offset %d: %s", p.pos, fmt.Sprintf(format, args...))
+}
+
+func (p *parser) skipSpace() {
+	for p.pos < len(p.s) && strings.IndexByte(" \t\r\n", p.s[p.pos]) >= 0 {
+		p.pos++
+	}
+}
+
+func (p *parser) word() string {
+	p.skipSpace()
+	start := p.pos
+	for p.pos < len(p.s) {
+		c := p.s[p.pos]
+		if !(c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z') {
+			break
+		}
+		p.pos++
+	}
+	return strings.ToUpper(p.s[start:p.pos])
+}
+
+// peek reports whether the next non-space character is c
+func (p *parser) peek(c byte) bool {
+	p.skipSpace()
+	return p.pos < len(p.s) && p.s[p.pos] == c
+}
+
+func (p *parser) expect(c byte) error {
+	if !p.peek(c) {
+		return p.errorf("expected %q", c)
+	}
+	p.pos++
+	return nil
+}
+
+func (p *parser) number() (float64, error) {
+	p.skipSpace()
+	start := p.pos
+	for p.pos < len(p.s) && strings.IndexByte("+-.0123456789eE", p.s[p.pos]) >= 0 {
+		p.pos++
+	}
+	f, err := strconv.ParseFloat(p.s[start:p.pos], 64)
+	if err != nil {
+		return 0, p.errorf("invalid number %q", p.s[start:p.pos])
+	}
+	return f, nil
+}
+
+func (p *parser) geometry(points *[]concaveman.Point) error {
+	typ := p.word()
+	p.dims = 2
+	switch dim := p.word(); dim {
+	case "":
+	case "Z", "M":
+		p.dims = 3
+	case "ZM":
+		p.dims = 4
+	case "EMPTY":
+		return nil
+	default:
+		return p.errorf("unexpected %q", dim)
+	}
+	if p.word() == "EMPTY" {
+		return nil
+	}
+
+	var depth int
+	switch typ {
+	case "POINT":
+		depth = 0
+	case "MULTIPOINT", "LINESTRING":
+		depth = 1
+	case "MULTILINESTRING", "POLYGON":
+		depth = 2
+	case "MULTIPOLYGON":
+		depth = 3
+	case "GEOMETRYCOLLECTION":
+		return p.list(func() error {
+			return p.geometry(points)
+		})
+	default:
+		return fmt.Errorf("%w %q", ErrUnsupportedType, typ)
+	}
+	return p.positions(points, depth, typ == "MULTIPOINT")
+}
+
+// list parses a parenthesized, comma-separated list
+func (p *parser) list(item func() error) error {
+	if err := p.expect('('); err != nil {
+		return err
+	}
+	for {
+		if err := item(); err != nil {
+			return err
+		}
+		if !p.peek(',') {
+			break
+		}
+		p.pos++
+	}
+	return p.expect(')')
+}
+
+// positions parses depth levels of nested lists of positions
+func (p *parser) positions(points *[]concaveman.Point, depth int, multiPoint bool) error {
+	return p.list(func() error {
+		if depth > 1 {
+			return p.positions(points, depth-1, false)
+		}
+		if p.word() == "EMPTY" {
+			return nil
+		}
+		// MULTIPOINT allows both (1 2, 3 4) and ((1 2), (3 4))
+		if multiPoint && p.peek('(') {
+			return p.list(func() error {
+				return p.position(points)
+			})
+		}
+		return p.position(points)
+	})
+}
+
+func (p *parser) position(points *[]concaveman.Point) error {
+	var pt concaveman.Point
+	for i := 0; i < p.dims; i++ {
+		f, err := p.number()
+		if err != nil {
+			return err
+		}
+		if i < 2 {
+			pt[i] = f
+		}
+	}
+	*points = append(*points, pt)
+	return nil
+}
+
+// Polygon formats a polygon, such as a hull wrapped in concaveman.Polygon, as
+// a WKT POLYGON. Coordinates are written in full precision. A polygon without
+// an outer ring, or with an empty one, is written as POLYGON EMPTY and empty
+// holes are left out. It returns ErrNonFinite if a coordinate is NaN or
+// infinite, as WKT has no way to write them.
+func Polygon(poly concaveman.Polygon) (string, error) {
+	var b strings.Builder
+	b.WriteString("POLYGON")
+	if err := writePolygon(&b, poly); err != nil {
+		return "", err
+	}
+	return b.String(), nil
+}
+
+// MultiPolygon formats polygons as a WKT MULTIPOLYGON, leaving out empty
+// ones. See Polygon.
+func MultiPolygon(polys []concaveman.Polygon) (string, error) {
+	var b strings.Builder
+	b.WriteString("MULTIPOLYGON")
+	var n int
+	for _, poly := range polys {
+		if empty(poly) {
+			continue
+		}
+		if n == 0 {
+			b.WriteByte('(')
+		} else {
+			b.WriteByte(',')
+		}
+		if err := writePolygon(&b, poly); err != nil {
+			return "", err
+		}
+		n++
+	}
+	if n == 0 {
+		b.WriteString(" EMPTY")
+	} else {
+		b.WriteByte(')')
+	}
+	return b.String(), nil
+}
+
+// ErrNonFinite is returned when writing a NaN or infinite coordinate.
+var ErrNonFinite = errors.New("wkt: non-finite coordinate")
+
+func empty(poly concaveman.Polygon) bool {
+	return len(poly) == 0 || len(poly[0]) == 0
+}
+
+func writePolygon(b *strings.Builder, poly concaveman.Polygon) error {
+	if empty(poly) {
+		b.WriteString(" EMPTY")
+		return nil
+	}
+	b.WriteByte('(')
+	for i, ring := range poly {
+		if len(ring) == 0 {
+			continue
+		}
+		if i > 0 {
+			b.WriteByte(',')
+		}
+		b.WriteByte('(')
+		for j, pt := range ring {
+			if math.IsNaN(pt[0]) || math.IsInf(pt[0], 0) || math.IsNaN(pt[1]) || math.IsInf(pt[1], 0) {
+				return fmt.Errorf("%w %v", ErrNonFinite, pt)
+			}
+			if j > 0 {
+				b.WriteByte(',')
+			}
+			b.WriteString(strconv.FormatFloat(pt[0], 'f', -1, 64))
+			b.WriteByte(' ')
+			b.WriteString(strconv.FormatFloat(pt[1], 'f', -1, 64))
+		}
+		b.WriteByte(')')
+	}
+	b.WriteByte(')')
+	return nil
+}
@@ -0,0 +1,144 @@
+package wkt_test
+
+import (
+	"errors"
+	"math"
+	"reflect"
+	"testing"
+
+	"github.com/wsw0108/concaveman-go"
+	"github.com/wsw0108/concaveman-go/wkt"
+)
+
+func TestDecode(t *testing.T) {
+	tests := []struct {
+		name string
+		s    string
+		want []concaveman.Point
+	}{
+		{
+			name: "POINT",
+			s:    "POINT (1 2)",
+			want: []concaveman.Point{{1, 2}},
+		},
+		{
+			name: "MULTIPOINT",
+			s:    "MULTIPOINT (1 2, 3 4)",
+			want: []concaveman.Point{{1, 2}, {3, 4}},
+		},
+		{
+			name: "MULTIPOINT/nested",
+			s:    "multipoint((1 2),(3 4))",
+			want: []concaveman.Point{{1, 2}, {3, 4}},
+		},
+		{
+			name: "LINESTRING Z",
+			s:    "LINESTRING Z (1 2 3, 4 5 6)",
+			want: []concaveman.Point{{1, 2}, {4, 5}},
+		},
+		{
+			name: "POLYGON",
+			s:    "POLYGON ((0 0, 1 0, 0 1, 0 0), (0.1 0.1, 0.2 0.1, 0.1 0.2, 0.1 0.1))",
+			want: []concaveman.Point{{0, 0}, {1, 0}, {0, 1}, {0, 0}, {0.1, 0.1}, {0.2, 0.1}, {0.1, 0.2}, {0.1, 0.1}},
+		},
+		{
+			name: "GEOMETRYCOLLECTION",
+			s:    "SRID=4326;GEOMETRYCOLLECTION (POINT (1 2), POINT EMPTY, MULTIPOLYGON (((-1e3 2.5, 3 4, 5 6, -1e3 2.5))))",
+			want: []concaveman.Point{{1, 2}, {-1000, 2.5}, {3, 4}, {5, 6}, {-1000, 2.5}},
+		},
+		{
+			name: "EMPTY",
+			s:    "MULTIPOINT EMPTY",
+			want: nil,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := wkt.Decode(tt.s)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Decode() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+
+	if _, err := wkt.Decode("CIRCLE (1 2)"); !errors.Is(err, wkt.ErrUnsupportedType) {
+		t.Errorf("Decode() error = %v", err)
+	}
+	for _, s := range []string{"POINT (1)", "POINT (1 2", "POINT (1 2) x", "LINESTRING (1 2, 3 y)"} {
+		if _, err := wkt.Decode(s); err == nil {
+			t.Errorf("Decode(%q) succeeded", s)
+		}
+	}
+}
+
+func TestPolygon(t *testing.T) {
+	poly := concaveman.Polygon{
+		{{0, 0}, {10, 0}, {0, 10}, {0, 0}},
+		{{1, 1}, {1, 2}, {2, 1}, {1, 1}},
+	}
+	s, err := wkt.Polygon(poly)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "POLYGON((0 0,10 0,0 10,0 0),(1 1,1 2,2 1,1 1))"
+	if s != want {
+		t.Errorf("Polygon() = %s, want %s", s, want)
+	}
+
+	s, err = wkt.MultiPolygon([]concaveman.Polygon{poly[:1], nil, poly[1:]})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want = "MULTIPOLYGON(((0 0,10 0,0 10,0 0)),((1 1,1 2,2 1,1 1)))"
+	if s != want {
+		t.Errorf("MultiPolygon() = %s, want %s", s, want)
+	}
+
+	// round trip in full precision
+	ring := []concaveman.Point{{-122.06851, 37.394206}, {0.1, 1e-300}, {1e300, -0.3}, {-122.06851, 37.394206}}
+	s, err = wkt.Polygon(concaveman.Polygon{ring})
+	if err != nil {
+		t.Fatal(err)
+	}
+	points, err := wkt.Decode(s)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(points, ring) {
+		t.Errorf("Decode(Polygon()) = %v, want %v", points, ring)
+	}
+}
+
+func TestPolygonInvalid(t *testing.T) {
+	tests := []struct {
+		name string
+		poly concaveman.Polygon
+		want string
+	}{
+		{"nil", nil, "POLYGON EMPTY"},
+		{"nil ring", concaveman.Polygon{nil}, "POLYGON EMPTY"},
+		{"empty hole", concaveman.Polygon{{{0, 0}, {1, 0}, {0, 1}, {0, 0}}, {}}, "POLYGON((0 0,1 0,0 1,0 0))"},
+	}
+	for _, tt := range tests {
+		s, err := wkt.Polygon(tt.poly)
+		if err != nil || s != tt.want {
+			t.Errorf("%s: Polygon() = %q, %v, want %q", tt.name, s, err, tt.want)
+		}
+	}
+	if s, err := wkt.MultiPolygon([]concaveman.Polygon{nil, {nil}}); err != nil || s != "MULTIPOLYGON EMPTY" {
+		t.Errorf("MultiPolygon() = %q, %v", s, err)
+	}
+
+	for _, pt := range []concaveman.Point{{math.NaN(), 0}, {0, math.Inf(-1)}} {
+		poly := concaveman.Polygon{{{0, 0}, pt, {0, 1}, {0, 0}}}
+		if _, err := wkt.Polygon(poly); !errors.Is(err, wkt.ErrNonFinite) {
+			t.Errorf("Polygon(%v) error = %v", pt, err)
+		}
+		if _, err := wkt.MultiPolygon([]concaveman.Polygon{poly}); !errors.Is(err, wkt.ErrNonFinite) {
+			t.Errorf("MultiPolygon(%v) error = %v", pt, err)
+		}
+	}
+}